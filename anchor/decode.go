@@ -0,0 +1,240 @@
+package anchor
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/big"
+	"slices"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// decoder reads borsh data, recording the first error. Values map to Go as
+// follows: structs to map[string]any (tuple structs to []any), unit enum
+// variants to their name, other variants to map[string]any{name: fields},
+// u128 and i128 to *big.Int, pubkeys to solana.PublicKey, bytes to []byte,
+// vectors and arrays to []any, and absent options to nil.
+type decoder struct {
+	idl   *IDL
+	data  []byte
+	pos   int
+	err   error
+	depth int
+}
+
+const maxTypeDepth = 64
+
+func (d *decoder) fail(format string, args ...any) {
+	if d.err == nil {
+		d.err = fmt.Errorf(format, args...)
+	}
+}
+
+func (d *decoder) next(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || d.pos+n > len(d.data) {
+		d.fail("data truncated at byte %d", d.pos)
+		return nil
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b
+}
+
+func (d *decoder) typeDef(def *typeDef) any {
+	if d.depth++; d.depth > maxTypeDepth {
+		d.fail("type nesting exceeds %d levels", maxTypeDepth)
+		return nil
+	}
+	defer func() { d.depth-- }()
+
+	switch def.Kind {
+	case "struct":
+		return d.fields(def.Fields)
+	case "enum":
+		tag := d.next(1)
+		if tag == nil {
+			return nil
+		}
+		if int(tag[0]) >= len(def.Variants) {
+			d.fail("enum variant %d out of range", tag[0])
+			return nil
+		}
+		v := def.Variants[tag[0]]
+		if len(v.Fields.Named) == 0 && len(v.Fields.Tuple) == 0 {
+			return v.Name
+		}
+		return map[string]any{v.Name: d.fields(v.Fields)}
+	case "alias", "type":
+		if def.Value == nil {
+			d.fail("alias without a value type")
+			return nil
+		}
+		return d.value(*def.Value)
+	default:
+		d.fail("unsupported type kind %q", def.Kind)
+		return nil
+	}
+}
+
+func (d *decoder) fields(fields fieldList) any {
+	if len(fields.Tuple) > 0 {
+		values := make([]any, len(fields.Tuple))
+		for i, t := range fields.Tuple {
+			values[i] = d.value(t)
+		}
+		return values
+	}
+
+	values := make(map[string]any, len(fields.Named))
+	for _, f := range fields.Named {
+		values[f.Name] = d.value(f.Type)
+	}
+	return values
+}
+
+func (d *decoder) value(t idlType) any {
+	if d.err != nil {
+		return nil
+	}
+
+	switch {
+	case t.Vec != nil:
+		b := d.next(4)
+		if b == nil {
+			return nil
+		}
+		n := int(binary.LittleEndian.Uint32(b))
+		if n > len(d.data)-d.pos {
+			d.fail("vector length %d exceeds remaining data", n)
+			return nil
+		}
+		if t.Vec.Primitive == "u8" {
+			return slices.Clone(d.next(n))
+		}
+		values := make([]any, 0, n)
+		for i := 0; i < n && d.err == nil; i++ {
+			values = append(values, d.value(*t.Vec))
+		}
+		return values
+
+	case t.Option != nil:
+		tag := d.next(1)
+		if tag == nil || tag[0] == 0 {
+			return nil
+		}
+		return d.value(*t.Option)
+
+	case t.COption != nil:
+		tag := d.next(4)
+		if tag == nil {
+			return nil
+		}
+		value := d.value(*t.COption)
+		if binary.LittleEndian.Uint32(tag) == 0 {
+			return nil
+		}
+		return value
+
+	case t.Array != nil:
+		values := make([]any, t.ArrayLen)
+		for i := range values {
+			values[i] = d.value(*t.Array)
+		}
+		return values
+
+	case t.Defined != "":
+		def, ok := d.idl.types[t.Defined]
+		if !ok {
+			d.fail("undefined type %q", t.Defined)
+			return nil
+		}
+		return d.typeDef(def)
+	}
+
+	return d.primitive(t.Primitive)
+}
+
+func (d *decoder) primitive(name string) any {
+	switch name {
+	case "bool":
+		if b := d.next(1); b != nil {
+			return b[0] != 0
+		}
+	case "u8":
+		if b := d.next(1); b != nil {
+			return b[0]
+		}
+	case "i8":
+		if b := d.next(1); b != nil {
+			return int8(b[0])
+		}
+	case "u16":
+		if b := d.next(2); b != nil {
+			return binary.LittleEndian.Uint16(b)
+		}
+	case "i16":
+		if b := d.next(2); b != nil {
+			return int16(binary.LittleEndian.Uint16(b))
+		}
+	case "u32":
+		if b := d.next(4); b != nil {
+			return binary.LittleEndian.Uint32(b)
+		}
+	case "i32":
+		if b := d.next(4); b != nil {
+			return int32(binary.LittleEndian.Uint32(b))
+		}
+	case "f32":
+		if b := d.next(4); b != nil {
+			return math.Float32frombits(binary.LittleEndian.Uint32(b))
+		}
+	case "u64":
+		if b := d.next(8); b != nil {
+			return binary.LittleEndian.Uint64(b)
+		}
+	case "i64":
+		if b := d.next(8); b != nil {
+			return int64(binary.LittleEndian.Uint64(b))
+		}
+	case "f64":
+		if b := d.next(8); b != nil {
+			return math.Float64frombits(binary.LittleEndian.Uint64(b))
+		}
+	case "u128", "i128":
+		if b := d.next(16); b != nil {
+			return int128(b, name == "i128")
+		}
+	case "pubkey", "publicKey":
+		if b := d.next(solana.PublicKeyLength); b != nil {
+			return solana.PublicKeyFromBytes(b)
+		}
+	case "string":
+		if b := d.next(4); b != nil {
+			return string(d.next(int(binary.LittleEndian.Uint32(b))))
+		}
+	case "bytes":
+		if b := d.next(4); b != nil {
+			return slices.Clone(d.next(int(binary.LittleEndian.Uint32(b))))
+		}
+	default:
+		d.fail("unsupported primitive type %q", name)
+	}
+	return nil
+}
+
+// int128 converts 16 little-endian bytes to a big.Int.
+func int128(b []byte, signed bool) *big.Int {
+	be := make([]byte, len(b))
+	for i := range b {
+		be[len(b)-1-i] = b[i]
+	}
+	v := new(big.Int).SetBytes(be)
+	if signed && be[0]&0x80 != 0 {
+		v.Sub(v, new(big.Int).Lsh(big.NewInt(1), 128))
+	}
+	return v
+}
@@ -0,0 +1,176 @@
+// Package anchor decodes Anchor program accounts using the program's IDL,
+// without hand-written structs. Both the legacy IDL format and the format
+// introduced in Anchor 0.30 are supported.
+package anchor
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+const DiscriminatorSize = 8
+
+type IDL struct {
+	Name string
+	// Address is the program ID, the zero key when the IDL does not say.
+	Address  solana.PublicKey
+	Accounts []AccountDef
+
+	types map[string]*typeDef
+}
+
+type AccountDef struct {
+	Name          string
+	Discriminator []byte
+
+	idl *IDL
+	typ *typeDef
+}
+
+// Account is a decoded account: its IDL type name and its fields.
+type Account struct {
+	Name   string
+	Fields map[string]any
+}
+
+type rawIDL struct {
+	Address  string `json:"address"`
+	Name     string `json:"name"`
+	Metadata struct {
+		Name    string `json:"name"`
+		Address string `json:"address"`
+	} `json:"metadata"`
+	Accounts []struct {
+		Name          string   `json:"name"`
+		Discriminator []int    `json:"discriminator"`
+		Type          *typeDef `json:"type"`
+	} `json:"accounts"`
+	Types []struct {
+		Name string   `json:"name"`
+		Type *typeDef `json:"type"`
+	} `json:"types"`
+}
+
+func LoadIDL(path string) (*IDL, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseIDL(data)
+}
+
+func ParseIDL(data []byte) (*IDL, error) {
+	var raw rawIDL
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parse idl: %w", err)
+	}
+
+	idl := &IDL{
+		Name:  raw.Name,
+		types: make(map[string]*typeDef),
+	}
+	if idl.Name == "" {
+		idl.Name = raw.Metadata.Name
+	}
+
+	address := raw.Address
+	if address == "" {
+		address = raw.Metadata.Address
+	}
+	if address != "" {
+		key, err := solana.PublicKeyFromBase58(address)
+		if err != nil {
+			return nil, fmt.Errorf("idl address: %w", err)
+		}
+		idl.Address = key
+	}
+
+	for _, t := range raw.Types {
+		if t.Type == nil {
+			return nil, fmt.Errorf("type %s has no definition", t.Name)
+		}
+		idl.types[t.Name] = t.Type
+	}
+
+	for _, a := range raw.Accounts {
+		def := AccountDef{Name: a.Name, idl: idl, typ: a.Type}
+
+		// Legacy IDLs inline the account type; newer ones refer to types by
+		// name and list the discriminator explicitly.
+		if def.typ == nil {
+			def.typ = idl.types[a.Name]
+		}
+		if def.typ == nil {
+			return nil, fmt.Errorf("account %s has no type definition", a.Name)
+		}
+
+		if len(a.Discriminator) > 0 {
+			def.Discriminator = make([]byte, len(a.Discriminator))
+			for i, b := range a.Discriminator {
+				if b < 0 || b > 255 {
+					return nil, fmt.Errorf("account %s discriminator byte %d out of range", a.Name, b)
+				}
+				def.Discriminator[i] = byte(b)
+			}
+		} else {
+			def.Discriminator = AccountDiscriminator(a.Name)
+		}
+
+		idl.Accounts = append(idl.Accounts, def)
+	}
+
+	return idl, nil
+}
+
+// AccountDiscriminator returns the legacy Anchor discriminator of an account
+// type: the first eight bytes of sha256("account:<name>").
+func AccountDiscriminator(name string) []byte {
+	sum := sha256.Sum256([]byte("account:" + name))
+	return sum[:DiscriminatorSize]
+}
+
+func (idl *IDL) account(data []byte) (*AccountDef, error) {
+	for i := range idl.Accounts {
+		def := &idl.Accounts[i]
+		if len(data) >= len(def.Discriminator) && string(data[:len(def.Discriminator)]) == string(def.Discriminator) {
+			return def, nil
+		}
+	}
+	return nil, fmt.Errorf("no account of %s matches discriminator %x", idl.Name, data[:min(len(data), DiscriminatorSize)])
+}
+
+// DecodeAccount picks the account type by discriminator and decodes the data
+// that follows it.
+func (idl *IDL) DecodeAccount(data []byte) (*Account, error) {
+	def, err := idl.account(data)
+	if err != nil {
+		return nil, err
+	}
+	return def.decode(data)
+}
+
+// Decode decodes data as this account type, discriminator included.
+func (def *AccountDef) Decode(data []byte) (*Account, error) {
+	if len(data) < len(def.Discriminator) || string(data[:len(def.Discriminator)]) != string(def.Discriminator) {
+		return nil, fmt.Errorf("data does not start with the %s discriminator", def.Name)
+	}
+	return def.decode(data)
+}
+
+func (def *AccountDef) decode(data []byte) (*Account, error) {
+	d := &decoder{idl: def.idl, data: data, pos: len(def.Discriminator)}
+	value := d.typeDef(def.typ)
+	if d.err != nil {
+		return nil, fmt.Errorf("decode %s: %w", def.Name, d.err)
+	}
+
+	fields, ok := value.(map[string]any)
+	if !ok {
+		fields = map[string]any{"value": value}
+	}
+	return &Account{Name: def.Name, Fields: fields}, nil
+}
@@ -0,0 +1,127 @@
+package anchor
+
+import (
+	"encoding/binary"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+const newFormatIDL = `{
+  "address": "6EF8rrecthR5Dkzon8Nwu78hRvfCKubJ14M5uBEwF6P",
+  "metadata": {"name": "pump", "version": "0.1.0", "spec": "0.1.0"},
+  "accounts": [{"name": "BondingCurve", "discriminator": [23, 183, 248, 55, 96, 216, 172, 96]}],
+  "types": [
+    {"name": "BondingCurve", "type": {"kind": "struct", "fields": [
+      {"name": "virtual_token_reserves", "type": "u64"},
+      {"name": "complete", "type": "bool"},
+      {"name": "creator", "type": "pubkey"},
+      {"name": "status", "type": {"defined": {"name": "Status"}}},
+      {"name": "fees", "type": {"vec": {"defined": {"name": "Fee"}}}},
+      {"name": "total", "type": "u128"},
+      {"name": "note", "type": {"option": "string"}},
+      {"name": "seeds", "type": {"array": ["u8", 2]}}
+    ]}},
+    {"name": "Status", "type": {"kind": "enum", "variants": [
+      {"name": "Active"},
+      {"name": "Paused", "fields": [{"name": "until", "type": "i64"}]}
+    ]}},
+    {"name": "Fee", "type": {"kind": "struct", "fields": ["u16", "u16"]}}
+  ]
+}`
+
+const legacyIDL = `{
+  "version": "0.1.0",
+  "name": "counter",
+  "accounts": [{"name": "Counter", "type": {"kind": "struct", "fields": [
+    {"name": "authority", "type": "publicKey"},
+    {"name": "count", "type": "u64"},
+    {"name": "kind", "type": {"defined": "Kind"}}
+  ]}}],
+  "types": [{"name": "Kind", "type": {"kind": "enum", "variants": [{"name": "A"}, {"name": "B"}]}}],
+  "metadata": {"address": "Fg6PaFpoGXkYsidMpWTK6W2BeZ7FEfcYkg476zPFsLnS"}
+}`
+
+func TestDecodeNewFormatAccount(t *testing.T) {
+	idl, err := ParseIDL([]byte(newFormatIDL))
+	if err != nil {
+		t.Fatalf("ParseIDL failed: %v", err)
+	}
+	if idl.Name != "pump" || idl.Address.String() != "6EF8rrecthR5Dkzon8Nwu78hRvfCKubJ14M5uBEwF6P" {
+		t.Errorf("Unexpected IDL header: %s %s", idl.Name, idl.Address)
+	}
+
+	creator := solana.NewWallet().PublicKey()
+	data := []byte{23, 183, 248, 55, 96, 216, 172, 96}
+	data = binary.LittleEndian.AppendUint64(data, 1_073_000_000)
+	data = append(data, 1)
+	data = append(data, creator.Bytes()...)
+	data = append(data, 1)
+	data = binary.LittleEndian.AppendUint64(data, uint64(1700000000))
+	data = binary.LittleEndian.AppendUint32(data, 1)
+	data = binary.LittleEndian.AppendUint16(data, 25)
+	data = binary.LittleEndian.AppendUint16(data, 100)
+	data = binary.LittleEndian.AppendUint64(data, 0)
+	data = binary.LittleEndian.AppendUint64(data, 1)
+	data = append(data, 0)
+	data = append(data, 7, 9)
+
+	account, err := idl.DecodeAccount(data)
+	if err != nil {
+		t.Fatalf("DecodeAccount failed: %v", err)
+	}
+	if account.Name != "BondingCurve" {
+		t.Errorf("Expected BondingCurve, got %s", account.Name)
+	}
+
+	f := account.Fields
+	if f["virtual_token_reserves"] != uint64(1_073_000_000) || f["complete"] != true || f["creator"] != creator {
+		t.Errorf("Unexpected scalar fields: %v", f)
+	}
+	if !reflect.DeepEqual(f["status"], map[string]any{"Paused": map[string]any{"until": int64(1700000000)}}) {
+		t.Errorf("Unexpected enum: %v", f["status"])
+	}
+	if !reflect.DeepEqual(f["fees"], []any{[]any{uint16(25), uint16(100)}}) {
+		t.Errorf("Unexpected fees: %v", f["fees"])
+	}
+	want := new(big.Int).Lsh(big.NewInt(1), 64)
+	if total, ok := f["total"].(*big.Int); !ok || total.Cmp(want) != 0 {
+		t.Errorf("Expected 2^64, got %v", f["total"])
+	}
+	if f["note"] != nil || !reflect.DeepEqual(f["seeds"], []any{uint8(7), uint8(9)}) {
+		t.Errorf("Unexpected option or array: %v %v", f["note"], f["seeds"])
+	}
+
+	if _, err := idl.DecodeAccount(data[:len(data)-1]); err == nil {
+		t.Error("Expected error for truncated data")
+	}
+}
+
+func TestDecodeLegacyAccount(t *testing.T) {
+	idl, err := ParseIDL([]byte(legacyIDL))
+	if err != nil {
+		t.Fatalf("ParseIDL failed: %v", err)
+	}
+	if idl.Address.String() != "Fg6PaFpoGXkYsidMpWTK6W2BeZ7FEfcYkg476zPFsLnS" {
+		t.Errorf("Unexpected address: %s", idl.Address)
+	}
+
+	authority := solana.NewWallet().PublicKey()
+	data := append(AccountDiscriminator("Counter"), authority.Bytes()...)
+	data = binary.LittleEndian.AppendUint64(data, 5)
+	data = append(data, 1)
+
+	account, err := idl.DecodeAccount(data)
+	if err != nil {
+		t.Fatalf("DecodeAccount failed: %v", err)
+	}
+	if account.Fields["authority"] != authority || account.Fields["count"] != uint64(5) || account.Fields["kind"] != "B" {
+		t.Errorf("Unexpected fields: %v", account.Fields)
+	}
+
+	if _, err := idl.DecodeAccount(make([]byte, 48)); err == nil {
+		t.Error("Expected error for unknown discriminator")
+	}
+}
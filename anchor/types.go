@@ -0,0 +1,115 @@
+package anchor
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+type typeDef struct {
+	Kind     string    `json:"kind"`
+	Fields   fieldList `json:"fields"`
+	Variants []variant `json:"variants"`
+	Value    *idlType  `json:"value"`
+}
+
+type variant struct {
+	Name   string    `json:"name"`
+	Fields fieldList `json:"fields"`
+}
+
+// fieldList holds either named fields or, for tuple structs and variants,
+// bare types.
+type fieldList struct {
+	Named []field
+	Tuple []idlType
+}
+
+type field struct {
+	Name string  `json:"name"`
+	Type idlType `json:"type"`
+}
+
+func (f *fieldList) UnmarshalJSON(data []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	for _, item := range raw {
+		var named field
+		if err := json.Unmarshal(item, &named); err == nil && named.Name != "" {
+			f.Named = append(f.Named, named)
+			continue
+		}
+		var t idlType
+		if err := json.Unmarshal(item, &t); err != nil {
+			return err
+		}
+		f.Tuple = append(f.Tuple, t)
+	}
+	if len(f.Named) > 0 && len(f.Tuple) > 0 {
+		return fmt.Errorf("fields mix named and tuple entries")
+	}
+	return nil
+}
+
+// idlType is a primitive name or one of the vec, option, coption, array and
+// defined forms.
+type idlType struct {
+	Primitive string
+	Vec       *idlType
+	Option    *idlType
+	COption   *idlType
+	Array     *idlType
+	ArrayLen  int
+	Defined   string
+}
+
+func (t *idlType) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &t.Primitive); err == nil {
+		return nil
+	}
+
+	var raw struct {
+		Vec     *idlType          `json:"vec"`
+		Option  *idlType          `json:"option"`
+		COption *idlType          `json:"coption"`
+		Array   []json.RawMessage `json:"array"`
+		Defined json.RawMessage   `json:"defined"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	switch {
+	case raw.Vec != nil:
+		t.Vec = raw.Vec
+	case raw.Option != nil:
+		t.Option = raw.Option
+	case raw.COption != nil:
+		t.COption = raw.COption
+	case raw.Array != nil:
+		if len(raw.Array) != 2 {
+			return fmt.Errorf("array type needs an element type and a length")
+		}
+		t.Array = &idlType{}
+		if err := json.Unmarshal(raw.Array[0], t.Array); err != nil {
+			return err
+		}
+		if err := json.Unmarshal(raw.Array[1], &t.ArrayLen); err != nil {
+			return fmt.Errorf("array length: %w", err)
+		}
+	case raw.Defined != nil:
+		if err := json.Unmarshal(raw.Defined, &t.Defined); err != nil {
+			var named struct {
+				Name string `json:"name"`
+			}
+			if err := json.Unmarshal(raw.Defined, &named); err != nil {
+				return err
+			}
+			t.Defined = named.Name
+		}
+	default:
+		return fmt.Errorf("unsupported type %s", data)
+	}
+	return nil
+}
@@ -0,0 +1,338 @@
+package yellowstone
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	pb "github.com/andrew-solarstorm/yellowstone-grpc-client-go/proto"
+	"github.com/gagliardetto/solana-go"
+)
+
+const (
+	defaultAssemblyTimeout = 10 * time.Second
+	defaultAssemblySlots   = 64
+)
+
+// BlockIncompleteError reports a slot that could not be assembled before its
+// timeout, or whose last entry does not hash to the block meta's blockhash.
+// Meta is nil when no block meta arrived.
+type BlockIncompleteError struct {
+	Slot                 uint64
+	Meta                 *pb.SubscribeUpdateBlockMeta
+	Transactions         uint64
+	ExpectedTransactions uint64
+	Entries              uint64
+	ExpectedEntries      uint64
+	BlockhashMismatch    bool
+}
+
+func (e *BlockIncompleteError) Error() string {
+	switch {
+	case e.BlockhashMismatch:
+		return fmt.Sprintf("block %d: last entry does not match blockhash %s", e.Slot, e.Meta.GetBlockhash())
+	case e.Meta == nil:
+		return fmt.Sprintf("block %d incomplete: no block meta, %d transactions, %d entries", e.Slot, e.Transactions, e.Entries)
+	default:
+		return fmt.Sprintf("block %d incomplete: %d/%d transactions, %d/%d entries",
+			e.Slot, e.Transactions, e.ExpectedTransactions, e.Entries, e.ExpectedEntries)
+	}
+}
+
+// BlockAssembler rebuilds full blocks from transaction, account, entry and
+// block meta updates, which are much lighter to subscribe to than blocks. A
+// slot is complete once its meta arrived together with as many transactions
+// and entries as the meta counts; the transaction filter must therefore
+// include vote and failed transactions. Accounts received until then are
+// included, keeping the latest write of each. Late updates for a slot that
+// was already emitted are dropped.
+type BlockAssembler struct {
+	mu           sync.Mutex
+	slots        map[uint64]*pendingBlock
+	done         map[uint64]struct{}
+	highest      uint64
+	timeout      time.Duration
+	maxSlots     int
+	onBlock      func(*pb.SubscribeUpdateBlock)
+	onIncomplete func(*BlockIncompleteError)
+	now          func() time.Time
+}
+
+type pendingBlock struct {
+	slot         uint64
+	started      time.Time
+	meta         *pb.SubscribeUpdateBlockMeta
+	transactions map[uint64]*pb.SubscribeUpdateTransactionInfo
+	accounts     map[string]*pb.SubscribeUpdateAccountInfo
+	entries      map[uint64]*pb.SubscribeUpdateEntry
+}
+
+func NewBlockAssembler() *BlockAssembler {
+	return &BlockAssembler{
+		slots:    make(map[uint64]*pendingBlock),
+		done:     make(map[uint64]struct{}),
+		timeout:  defaultAssemblyTimeout,
+		maxSlots: defaultAssemblySlots,
+		now:      time.Now,
+	}
+}
+
+func (a *BlockAssembler) OnBlock(fn func(*pb.SubscribeUpdateBlock)) *BlockAssembler {
+	a.onBlock = fn
+	return a
+}
+
+func (a *BlockAssembler) OnIncomplete(fn func(*BlockIncompleteError)) *BlockAssembler {
+	a.onIncomplete = fn
+	return a
+}
+
+// Timeout sets how long after its first update a slot may stay incomplete.
+func (a *BlockAssembler) Timeout(timeout time.Duration) *BlockAssembler {
+	a.timeout = timeout
+	return a
+}
+
+// MaxSlots bounds the number of slots assembled at once; the oldest slot is
+// reported incomplete when a new one would exceed it.
+func (a *BlockAssembler) MaxSlots(n int) *BlockAssembler {
+	a.maxSlots = n
+	return a
+}
+
+func (a *BlockAssembler) Handle(update *pb.SubscribeUpdate) error {
+	a.mu.Lock()
+	var (
+		blocks []*pb.SubscribeUpdateBlock
+		errs   []*BlockIncompleteError
+	)
+	if slot, ok := assemblySlot(update); ok {
+		if block := a.add(slot, update); block != nil {
+			if block.meta.GetBlockhash() != lastEntryHash(block) {
+				errs = append(errs, block.incomplete(true))
+			} else {
+				blocks = append(blocks, block.assemble())
+			}
+		}
+	}
+	errs = append(errs, a.sweep()...)
+	a.mu.Unlock()
+
+	a.emit(blocks, errs)
+	return nil
+}
+
+// Sweep reports slots that timed out. Handle sweeps on every update; call
+// Sweep periodically when the stream may go quiet.
+func (a *BlockAssembler) Sweep() {
+	a.mu.Lock()
+	errs := a.sweep()
+	a.mu.Unlock()
+	a.emit(nil, errs)
+}
+
+func (a *BlockAssembler) emit(blocks []*pb.SubscribeUpdateBlock, errs []*BlockIncompleteError) {
+	if a.onBlock != nil {
+		for _, block := range blocks {
+			a.onBlock(block)
+		}
+	}
+	if a.onIncomplete != nil {
+		for _, err := range errs {
+			a.onIncomplete(err)
+		}
+	}
+}
+
+// add files update under its slot and returns the slot's pending block once
+// it is complete.
+func (a *BlockAssembler) add(slot uint64, update *pb.SubscribeUpdate) *pendingBlock {
+	if _, ok := a.done[slot]; ok {
+		return nil
+	}
+
+	block, ok := a.slots[slot]
+	if !ok {
+		block = newPendingBlock(slot, a.now())
+		a.slots[slot] = block
+		if slot > a.highest {
+			a.highest = slot
+		}
+	}
+	block.add(update)
+
+	if !block.complete() {
+		return nil
+	}
+	a.finish(slot)
+	return block
+}
+
+func (a *BlockAssembler) finish(slot uint64) {
+	delete(a.slots, slot)
+	a.done[slot] = struct{}{}
+
+	// Forget emitted slots once they are well behind the newest one.
+	window := uint64(4 * defaultAssemblySlots)
+	if a.maxSlots > 0 {
+		window = uint64(4 * a.maxSlots)
+	}
+	if a.highest > window {
+		for s := range a.done {
+			if s < a.highest-window {
+				delete(a.done, s)
+			}
+		}
+	}
+}
+
+func (a *BlockAssembler) sweep() []*BlockIncompleteError {
+	var expired []uint64
+	now := a.now()
+	for slot, block := range a.slots {
+		if now.Sub(block.started) >= a.timeout {
+			expired = append(expired, slot)
+		}
+	}
+	sort.Slice(expired, func(i, j int) bool { return expired[i] < expired[j] })
+
+	if a.maxSlots > 0 && len(a.slots)-len(expired) > a.maxSlots {
+		timedOut := make(map[uint64]bool, len(expired))
+		for _, slot := range expired {
+			timedOut[slot] = true
+		}
+		pending := make([]uint64, 0, len(a.slots))
+		for slot := range a.slots {
+			if !timedOut[slot] {
+				pending = append(pending, slot)
+			}
+		}
+		sort.Slice(pending, func(i, j int) bool { return pending[i] < pending[j] })
+		expired = append(expired, pending[:len(pending)-a.maxSlots]...)
+	}
+
+	errs := make([]*BlockIncompleteError, 0, len(expired))
+	for _, slot := range expired {
+		errs = append(errs, a.slots[slot].incomplete(false))
+		a.finish(slot)
+	}
+	return errs
+}
+
+func newPendingBlock(slot uint64, started time.Time) *pendingBlock {
+	return &pendingBlock{
+		slot:         slot,
+		started:      started,
+		transactions: make(map[uint64]*pb.SubscribeUpdateTransactionInfo),
+		accounts:     make(map[string]*pb.SubscribeUpdateAccountInfo),
+		entries:      make(map[uint64]*pb.SubscribeUpdateEntry),
+	}
+}
+
+func (b *pendingBlock) add(update *pb.SubscribeUpdate) {
+	switch u := update.GetUpdateOneof().(type) {
+	case *pb.SubscribeUpdate_BlockMeta:
+		b.meta = u.BlockMeta
+	case *pb.SubscribeUpdate_Transaction:
+		if tx := u.Transaction.GetTransaction(); tx != nil {
+			b.transactions[tx.GetIndex()] = tx
+		}
+	case *pb.SubscribeUpdate_Account:
+		info := u.Account.GetAccount()
+		key := string(info.GetPubkey())
+		if prev, ok := b.accounts[key]; !ok || info.GetWriteVersion() >= prev.GetWriteVersion() {
+			b.accounts[key] = info
+		}
+	case *pb.SubscribeUpdate_Entry:
+		b.entries[u.Entry.GetIndex()] = u.Entry
+	}
+}
+
+func (b *pendingBlock) complete() bool {
+	return b.meta != nil &&
+		uint64(len(b.transactions)) == b.meta.GetExecutedTransactionCount() &&
+		uint64(len(b.entries)) == b.meta.GetEntriesCount()
+}
+
+func (b *pendingBlock) incomplete(mismatch bool) *BlockIncompleteError {
+	return &BlockIncompleteError{
+		Slot:                 b.slot,
+		Meta:                 b.meta,
+		Transactions:         uint64(len(b.transactions)),
+		ExpectedTransactions: b.meta.GetExecutedTransactionCount(),
+		Entries:              uint64(len(b.entries)),
+		ExpectedEntries:      b.meta.GetEntriesCount(),
+		BlockhashMismatch:    mismatch,
+	}
+}
+
+func (b *pendingBlock) assemble() *pb.SubscribeUpdateBlock {
+	meta := b.meta
+	block := &pb.SubscribeUpdateBlock{
+		Slot:                     b.slot,
+		Blockhash:                meta.GetBlockhash(),
+		Rewards:                  meta.GetRewards(),
+		BlockTime:                meta.GetBlockTime(),
+		BlockHeight:              meta.GetBlockHeight(),
+		ParentSlot:               meta.GetParentSlot(),
+		ParentBlockhash:          meta.GetParentBlockhash(),
+		ExecutedTransactionCount: meta.GetExecutedTransactionCount(),
+		UpdatedAccountCount:      uint64(len(b.accounts)),
+		EntriesCount:             meta.GetEntriesCount(),
+	}
+
+	for _, tx := range b.transactions {
+		block.Transactions = append(block.Transactions, tx)
+	}
+	sort.Slice(block.Transactions, func(i, j int) bool {
+		return block.Transactions[i].GetIndex() < block.Transactions[j].GetIndex()
+	})
+
+	for _, info := range b.accounts {
+		block.Accounts = append(block.Accounts, info)
+	}
+	sort.Slice(block.Accounts, func(i, j int) bool {
+		return block.Accounts[i].GetWriteVersion() < block.Accounts[j].GetWriteVersion()
+	})
+
+	for _, entry := range b.entries {
+		block.Entries = append(block.Entries, entry)
+	}
+	sort.Slice(block.Entries, func(i, j int) bool {
+		return block.Entries[i].GetIndex() < block.Entries[j].GetIndex()
+	})
+	return block
+}
+
+// assemblySlot returns the slot of the updates a block is assembled from.
+// Startup account snapshots belong to no block.
+func assemblySlot(update *pb.SubscribeUpdate) (uint64, bool) {
+	switch u := update.GetUpdateOneof().(type) {
+	case *pb.SubscribeUpdate_BlockMeta:
+		return u.BlockMeta.GetSlot(), true
+	case *pb.SubscribeUpdate_Transaction:
+		return u.Transaction.GetSlot(), true
+	case *pb.SubscribeUpdate_Account:
+		return u.Account.GetSlot(), !u.Account.GetIsStartup()
+	case *pb.SubscribeUpdate_Entry:
+		return u.Entry.GetSlot(), true
+	default:
+		return 0, false
+	}
+}
+
+// lastEntryHash returns the base58 hash of the block's last entry, which is
+// the blockhash. A block without entries has nothing to check against.
+func lastEntryHash(b *pendingBlock) string {
+	var last *pb.SubscribeUpdateEntry
+	for _, entry := range b.entries {
+		if last == nil || entry.GetIndex() > last.GetIndex() {
+			last = entry
+		}
+	}
+	if last == nil || len(last.GetHash()) != len(solana.Hash{}) {
+		return b.meta.GetBlockhash()
+	}
+	return solana.HashFromBytes(last.GetHash()).String()
+}
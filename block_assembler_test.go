@@ -0,0 +1,136 @@
+package yellowstone
+
+import (
+	"testing"
+	"time"
+
+	pb "github.com/andrew-solarstorm/yellowstone-grpc-client-go/proto"
+	"github.com/gagliardetto/solana-go"
+)
+
+func assemblyUpdates(slot uint64, blockhash solana.Hash) (meta *pb.SubscribeUpdate, parts []*pb.SubscribeUpdate) {
+	meta = &pb.SubscribeUpdate{UpdateOneof: &pb.SubscribeUpdate_BlockMeta{BlockMeta: &pb.SubscribeUpdateBlockMeta{
+		Slot:                     slot,
+		Blockhash:                blockhash.String(),
+		ParentSlot:               slot - 1,
+		ExecutedTransactionCount: 2,
+		EntriesCount:             2,
+	}}}
+
+	for _, index := range []uint64{1, 0} {
+		parts = append(parts, &pb.SubscribeUpdate{UpdateOneof: &pb.SubscribeUpdate_Transaction{Transaction: &pb.SubscribeUpdateTransaction{
+			Slot:        slot,
+			Transaction: &pb.SubscribeUpdateTransactionInfo{Signature: []byte{byte(index)}, Index: index},
+		}}})
+	}
+	for _, writeVersion := range []uint64{5, 3} {
+		parts = append(parts, &pb.SubscribeUpdate{UpdateOneof: &pb.SubscribeUpdate_Account{Account: &pb.SubscribeUpdateAccount{
+			Slot:    slot,
+			Account: &pb.SubscribeUpdateAccountInfo{Pubkey: []byte{1}, WriteVersion: writeVersion},
+		}}})
+	}
+	parts = append(parts,
+		&pb.SubscribeUpdate{UpdateOneof: &pb.SubscribeUpdate_Entry{Entry: &pb.SubscribeUpdateEntry{Slot: slot, Index: 1, Hash: blockhash[:]}}},
+		&pb.SubscribeUpdate{UpdateOneof: &pb.SubscribeUpdate_Entry{Entry: &pb.SubscribeUpdateEntry{Slot: slot, Index: 0, Hash: make([]byte, 32)}}},
+	)
+	return meta, parts
+}
+
+func TestBlockAssemblerCompletes(t *testing.T) {
+	var (
+		blocks []*pb.SubscribeUpdateBlock
+		errs   []*BlockIncompleteError
+	)
+	assembler := NewBlockAssembler().
+		OnBlock(func(block *pb.SubscribeUpdateBlock) { blocks = append(blocks, block) }).
+		OnIncomplete(func(err *BlockIncompleteError) { errs = append(errs, err) })
+
+	blockhash := solana.Hash{7}
+	meta, parts := assemblyUpdates(100, blockhash)
+	assembler.Handle(meta)
+	for _, update := range parts {
+		assembler.Handle(update)
+	}
+
+	if len(errs) != 0 || len(blocks) != 1 {
+		t.Fatalf("Expected one block, got %d blocks and errors %v", len(blocks), errs)
+	}
+	block := blocks[0]
+	if block.Slot != 100 || block.ParentSlot != 99 || block.Blockhash != blockhash.String() {
+		t.Fatalf("Unexpected block header: %+v", block)
+	}
+	if len(block.Transactions) != 2 || block.Transactions[0].Index != 0 || block.Transactions[1].Index != 1 {
+		t.Fatalf("Transactions should be ordered by index: %+v", block.Transactions)
+	}
+	if block.UpdatedAccountCount != 1 || block.Accounts[0].WriteVersion != 5 {
+		t.Fatalf("Expected the latest account write, got %+v", block.Accounts)
+	}
+	if len(block.Entries) != 2 || block.Entries[1].Index != 1 {
+		t.Fatalf("Entries should be ordered by index: %+v", block.Entries)
+	}
+
+	assembler.Handle(parts[0])
+	if len(blocks) != 1 || len(errs) != 0 {
+		t.Fatal("Late updates of an emitted slot should be dropped")
+	}
+}
+
+func TestBlockAssemblerBlockhashMismatch(t *testing.T) {
+	var errs []*BlockIncompleteError
+	assembler := NewBlockAssembler().
+		OnBlock(func(*pb.SubscribeUpdateBlock) { t.Fatal("Unexpected block") }).
+		OnIncomplete(func(err *BlockIncompleteError) { errs = append(errs, err) })
+
+	meta, parts := assemblyUpdates(100, solana.Hash{7})
+	meta.GetBlockMeta().Blockhash = solana.Hash{8}.String()
+	for _, update := range append(parts, meta) {
+		assembler.Handle(update)
+	}
+
+	if len(errs) != 1 || !errs[0].BlockhashMismatch {
+		t.Fatalf("Expected a blockhash mismatch, got %v", errs)
+	}
+}
+
+func TestBlockAssemblerTimeout(t *testing.T) {
+	now := time.Unix(0, 0)
+	var errs []*BlockIncompleteError
+	assembler := NewBlockAssembler().
+		Timeout(time.Second).
+		OnIncomplete(func(err *BlockIncompleteError) { errs = append(errs, err) })
+	assembler.now = func() time.Time { return now }
+
+	meta, parts := assemblyUpdates(100, solana.Hash{7})
+	assembler.Handle(meta)
+	assembler.Handle(parts[0])
+
+	now = now.Add(2 * time.Second)
+	assembler.Sweep()
+
+	if len(errs) != 1 {
+		t.Fatalf("Expected one incomplete block, got %v", errs)
+	}
+	err := errs[0]
+	if err.Slot != 100 || err.Transactions != 1 || err.ExpectedTransactions != 2 || err.Entries != 0 || err.ExpectedEntries != 2 {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+	if err.Error() != "block 100 incomplete: 1/2 transactions, 0/2 entries" {
+		t.Fatalf("Unexpected message: %s", err)
+	}
+}
+
+func TestBlockAssemblerMaxSlots(t *testing.T) {
+	var errs []*BlockIncompleteError
+	assembler := NewBlockAssembler().
+		MaxSlots(2).
+		OnIncomplete(func(err *BlockIncompleteError) { errs = append(errs, err) })
+
+	for slot := uint64(1); slot <= 3; slot++ {
+		_, parts := assemblyUpdates(slot, solana.Hash{7})
+		assembler.Handle(parts[0])
+	}
+
+	if len(errs) != 1 || errs[0].Slot != 1 || errs[0].Meta != nil {
+		t.Fatalf("Expected the oldest slot to be dropped, got %v", errs)
+	}
+}
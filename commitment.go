@@ -0,0 +1,286 @@
+package yellowstone
+
+import (
+	"sort"
+	"sync"
+
+	pb "github.com/andrew-solarstorm/yellowstone-grpc-client-go/proto"
+)
+
+const (
+	defaultCommitmentRetention  = 150
+	defaultCommitmentMaxUpdates = 100_000
+)
+
+type PromotionStatus uint8
+
+const (
+	PromotionConfirmed PromotionStatus = iota + 1
+	PromotionFinalized
+	// PromotionDiscarded means the slot died or was left off the finalized
+	// chain.
+	PromotionDiscarded
+	// PromotionExpired means the update was evicted by the retention limits
+	// before its slot was finalized or discarded.
+	PromotionExpired
+)
+
+func (s PromotionStatus) String() string {
+	switch s {
+	case PromotionConfirmed:
+		return "confirmed"
+	case PromotionFinalized:
+		return "finalized"
+	case PromotionDiscarded:
+		return "discarded"
+	case PromotionExpired:
+		return "expired"
+	default:
+		return "unknown"
+	}
+}
+
+type Promotion struct {
+	Slot   uint64
+	Status PromotionStatus
+	Update *pb.SubscribeUpdate
+}
+
+// CommitmentBuffer holds updates received at processed commitment, keyed by
+// slot, and reports each one again when slot status updates on the same
+// stream confirm, finalize or discard its slot. A confirmed update stays
+// buffered until it is finalized. Slots older than the retention window, and
+// the oldest slots beyond MaxUpdates, are expired.
+type CommitmentBuffer struct {
+	mu          sync.Mutex
+	forks       *ForkTracker
+	slots       map[uint64]*commitmentSlot
+	buffered    int
+	highest     uint64
+	retention   uint64
+	maxUpdates  int
+	onPromotion func(Promotion)
+
+	// rollbacks collects the ForkTracker callbacks of one Handle call.
+	rollbacks []Rollback
+}
+
+type commitmentSlot struct {
+	updates   []*pb.SubscribeUpdate
+	confirmed bool
+	finalized bool
+	discarded bool
+}
+
+func NewCommitmentBuffer() *CommitmentBuffer {
+	b := &CommitmentBuffer{
+		slots:      make(map[uint64]*commitmentSlot),
+		retention:  defaultCommitmentRetention,
+		maxUpdates: defaultCommitmentMaxUpdates,
+	}
+	b.forks = NewForkTracker().Window(defaultCommitmentRetention).OnRollback(func(r Rollback) {
+		b.rollbacks = append(b.rollbacks, r)
+	})
+	return b
+}
+
+func (b *CommitmentBuffer) OnPromotion(fn func(Promotion)) *CommitmentBuffer {
+	b.onPromotion = fn
+	return b
+}
+
+// Retention sets how many slots behind the highest slot are kept.
+func (b *CommitmentBuffer) Retention(slots uint64) *CommitmentBuffer {
+	b.retention = slots
+	b.forks.Window(slots)
+	return b
+}
+
+// MaxUpdates bounds the number of buffered updates; zero means no bound.
+func (b *CommitmentBuffer) MaxUpdates(n int) *CommitmentBuffer {
+	b.maxUpdates = n
+	return b
+}
+
+// Len returns the number of buffered updates.
+func (b *CommitmentBuffer) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buffered
+}
+
+// Handle buffers update or, for a slot update, promotes the buffered updates
+// it affects. Pings and pongs are ignored.
+func (b *CommitmentBuffer) Handle(update *pb.SubscribeUpdate) error {
+	b.mu.Lock()
+	var promotions []Promotion
+	if slot := update.GetSlot(); slot != nil {
+		promotions = b.observe(slot)
+	} else if slot, ok := UpdateSlot(update); ok {
+		promotions = b.add(slot, update)
+	}
+	promotions = append(promotions, b.evict()...)
+	b.mu.Unlock()
+
+	if b.onPromotion != nil {
+		for _, promotion := range promotions {
+			b.onPromotion(promotion)
+		}
+	}
+	return nil
+}
+
+func (b *CommitmentBuffer) add(slot uint64, update *pb.SubscribeUpdate) []Promotion {
+	if b.highest > b.retention && slot < b.highest-b.retention {
+		return []Promotion{{Slot: slot, Status: PromotionExpired, Update: update}}
+	}
+	state := b.slot(slot)
+	switch {
+	case state.discarded:
+		return []Promotion{{Slot: slot, Status: PromotionDiscarded, Update: update}}
+	case state.finalized:
+		return []Promotion{{Slot: slot, Status: PromotionFinalized, Update: update}}
+	}
+
+	state.updates = append(state.updates, update)
+	b.buffered++
+	if state.confirmed {
+		return []Promotion{{Slot: slot, Status: PromotionConfirmed, Update: update}}
+	}
+	return nil
+}
+
+func (b *CommitmentBuffer) observe(update *pb.SubscribeUpdateSlot) []Promotion {
+	slot := update.GetSlot()
+	if b.highest > b.retention && slot < b.highest-b.retention {
+		return nil
+	}
+
+	var status PromotionStatus
+	switch update.GetStatus() {
+	case pb.SlotStatus_SLOT_CONFIRMED:
+		status = PromotionConfirmed
+	case pb.SlotStatus_SLOT_FINALIZED:
+		status = PromotionFinalized
+	}
+
+	// The chain is read before the tracker prunes below a finalized slot.
+	var chain []uint64
+	if status != 0 {
+		chain = b.chain(slot)
+	}
+
+	b.rollbacks = b.rollbacks[:0]
+	b.forks.Observe(update)
+
+	var promotions []Promotion
+	for _, s := range chain {
+		promotions = append(promotions, b.promote(s, status)...)
+	}
+	for _, rollback := range b.rollbacks {
+		promotions = append(promotions, b.promote(rollback.Slot, PromotionDiscarded)...)
+	}
+	return promotions
+}
+
+// chain returns slot and its known ancestors that have buffer state.
+func (b *CommitmentBuffer) chain(slot uint64) []uint64 {
+	chain := []uint64{slot}
+	for {
+		info, ok := b.forks.Slot(slot)
+		if !ok || !info.HasParent || info.Parent >= slot {
+			break
+		}
+		slot = info.Parent
+		if _, ok := b.slots[slot]; ok {
+			chain = append(chain, slot)
+		}
+	}
+	sort.Slice(chain, func(i, j int) bool { return chain[i] < chain[j] })
+	return chain
+}
+
+func (b *CommitmentBuffer) promote(slot uint64, status PromotionStatus) []Promotion {
+	state := b.slot(slot)
+	if state.finalized || state.discarded {
+		return nil
+	}
+
+	switch status {
+	case PromotionConfirmed:
+		if state.confirmed {
+			return nil
+		}
+		state.confirmed = true
+	case PromotionFinalized:
+		state.confirmed = true
+		state.finalized = true
+	case PromotionDiscarded:
+		state.discarded = true
+	}
+
+	promotions := make([]Promotion, len(state.updates))
+	for i, update := range state.updates {
+		promotions[i] = Promotion{Slot: slot, Status: status, Update: update}
+	}
+	if state.finalized || state.discarded {
+		b.buffered -= len(state.updates)
+		state.updates = nil
+	}
+	return promotions
+}
+
+func (b *CommitmentBuffer) slot(slot uint64) *commitmentSlot {
+	state, ok := b.slots[slot]
+	if !ok {
+		state = &commitmentSlot{}
+		b.slots[slot] = state
+	}
+	if slot > b.highest {
+		b.highest = slot
+	}
+	return state
+}
+
+// evict expires slots that left the retention window, then the oldest slots
+// until the buffer fits MaxUpdates.
+func (b *CommitmentBuffer) evict() []Promotion {
+	var expired []uint64
+	if b.highest > b.retention {
+		horizon := b.highest - b.retention
+		for slot := range b.slots {
+			if slot < horizon {
+				expired = append(expired, slot)
+			}
+		}
+	}
+	sort.Slice(expired, func(i, j int) bool { return expired[i] < expired[j] })
+
+	var promotions []Promotion
+	expire := func(slot uint64) {
+		state := b.slots[slot]
+		for _, update := range state.updates {
+			promotions = append(promotions, Promotion{Slot: slot, Status: PromotionExpired, Update: update})
+		}
+		b.buffered -= len(state.updates)
+		delete(b.slots, slot)
+	}
+	for _, slot := range expired {
+		expire(slot)
+	}
+
+	if b.maxUpdates > 0 && b.buffered > b.maxUpdates {
+		slots := make([]uint64, 0, len(b.slots))
+		for slot := range b.slots {
+			slots = append(slots, slot)
+		}
+		sort.Slice(slots, func(i, j int) bool { return slots[i] < slots[j] })
+		for _, slot := range slots {
+			if b.buffered <= b.maxUpdates {
+				break
+			}
+			expire(slot)
+		}
+	}
+	return promotions
+}
@@ -0,0 +1,99 @@
+package yellowstone
+
+import (
+	"testing"
+
+	pb "github.com/andrew-solarstorm/yellowstone-grpc-client-go/proto"
+)
+
+func slotStatusUpdate(slot, parent uint64, status pb.SlotStatus) *pb.SubscribeUpdate {
+	return &pb.SubscribeUpdate{
+		UpdateOneof: &pb.SubscribeUpdate_Slot{Slot: slotStatus(slot, parent, status)},
+	}
+}
+
+type promotionLog []Promotion
+
+func (l promotionLog) count(slot uint64, status PromotionStatus) int {
+	n := 0
+	for _, p := range l {
+		if p.Slot == slot && p.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+func TestCommitmentBufferPromotes(t *testing.T) {
+	var log promotionLog
+	buffer := NewCommitmentBuffer().OnPromotion(func(p Promotion) { log = append(log, p) })
+
+	for _, update := range []*pb.SubscribeUpdate{
+		slotStatusUpdate(10, 9, pb.SlotStatus_SLOT_PROCESSED),
+		transactionStatusUpdate(10),
+		transactionStatusUpdate(10),
+		slotStatusUpdate(11, 10, pb.SlotStatus_SLOT_PROCESSED),
+		slotStatusUpdate(12, 10, pb.SlotStatus_SLOT_PROCESSED),
+		transactionStatusUpdate(11),
+		transactionStatusUpdate(12),
+		slotStatusUpdate(10, 0, pb.SlotStatus_SLOT_CONFIRMED),
+	} {
+		buffer.Handle(update)
+	}
+	if log.count(10, PromotionConfirmed) != 2 || len(log) != 2 {
+		t.Fatalf("Expected two confirmations for slot 10, got %+v", log)
+	}
+
+	log = nil
+	buffer.Handle(slotStatusUpdate(12, 0, pb.SlotStatus_SLOT_FINALIZED))
+	if log.count(10, PromotionFinalized) != 2 || log.count(12, PromotionFinalized) != 1 || log.count(11, PromotionDiscarded) != 1 {
+		t.Fatalf("Unexpected promotions: %+v", log)
+	}
+	if buffer.Len() != 0 {
+		t.Fatalf("Expected an empty buffer, got %d", buffer.Len())
+	}
+
+	log = nil
+	buffer.Handle(transactionStatusUpdate(11))
+	buffer.Handle(transactionStatusUpdate(12))
+	if log.count(11, PromotionDiscarded) != 1 || log.count(12, PromotionFinalized) != 1 {
+		t.Fatalf("Late updates should be settled at once, got %+v", log)
+	}
+}
+
+func TestCommitmentBufferDeadSlot(t *testing.T) {
+	var log promotionLog
+	buffer := NewCommitmentBuffer().OnPromotion(func(p Promotion) { log = append(log, p) })
+
+	buffer.Handle(slotStatusUpdate(20, 19, pb.SlotStatus_SLOT_CREATED_BANK))
+	buffer.Handle(transactionStatusUpdate(20))
+	buffer.Handle(slotStatusUpdate(20, 0, pb.SlotStatus_SLOT_DEAD))
+
+	if log.count(20, PromotionDiscarded) != 1 || len(log) != 1 {
+		t.Fatalf("Expected the update to be discarded, got %+v", log)
+	}
+}
+
+func TestCommitmentBufferLimits(t *testing.T) {
+	var log promotionLog
+	buffer := NewCommitmentBuffer().
+		Retention(5).
+		MaxUpdates(3).
+		OnPromotion(func(p Promotion) { log = append(log, p) })
+
+	for slot := uint64(1); slot <= 4; slot++ {
+		buffer.Handle(transactionStatusUpdate(slot))
+	}
+	if log.count(1, PromotionExpired) != 1 || buffer.Len() != 3 {
+		t.Fatalf("Expected the oldest update to expire, got %+v (len %d)", log, buffer.Len())
+	}
+
+	log = nil
+	buffer.Handle(transactionStatusUpdate(10))
+	if log.count(2, PromotionExpired) != 1 || log.count(3, PromotionExpired) != 1 || log.count(4, PromotionExpired) != 1 {
+		t.Fatalf("Expected slots outside the retention window to expire, got %+v", log)
+	}
+	if buffer.Len() != 1 {
+		t.Fatalf("Expected one buffered update, got %d", buffer.Len())
+	}
+}
@@ -0,0 +1,69 @@
+package yellowstone
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/encoding/gzip"
+)
+
+const (
+	CompressionGzip = gzip.Name
+	CompressionZstd = "zstd"
+)
+
+// gRPC only advertises and decodes compressors from its process-wide
+// registry, so both are registered at init and accepted on every connection.
+func init() {
+	encoder, err := zstd.NewWriter(nil)
+	if err != nil {
+		panic(err)
+	}
+	decoder, err := zstd.NewReader(nil)
+	if err != nil {
+		panic(err)
+	}
+	encoding.RegisterCompressor(&zstdCompressor{encoder: encoder, decoder: decoder})
+}
+
+type zstdCompressor struct {
+	encoder *zstd.Encoder
+	decoder *zstd.Decoder
+}
+
+func (c *zstdCompressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	return &zstdWriter{encoder: c.encoder, w: w}, nil
+}
+
+func (c *zstdCompressor) Decompress(r io.Reader) (io.Reader, error) {
+	compressed, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data, err := c.decoder.DecodeAll(compressed, nil)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(data), nil
+}
+
+func (c *zstdCompressor) Name() string {
+	return CompressionZstd
+}
+
+type zstdWriter struct {
+	encoder *zstd.Encoder
+	w       io.Writer
+	buf     bytes.Buffer
+}
+
+func (z *zstdWriter) Write(p []byte) (int, error) {
+	return z.buf.Write(p)
+}
+
+func (z *zstdWriter) Close() error {
+	_, err := z.w.Write(z.encoder.EncodeAll(z.buf.Bytes(), nil))
+	return err
+}
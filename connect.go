@@ -0,0 +1,125 @@
+package yellowstone
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+type ConnectProbe int
+
+const (
+	ConnectProbeNone ConnectProbe = iota
+	ConnectProbeVersion
+	ConnectProbeHealth
+)
+
+type connectRecorder struct {
+	mu    sync.Mutex
+	err   error
+	isTLS bool
+}
+
+func (r *connectRecorder) record(err error, isTLS bool) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.err = err
+	r.isTLS = isTLS
+}
+
+func (r *connectRecorder) classify(fallback error) *GeyserGrpcBuilderError {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch {
+	case r.err == nil:
+		return NewDialError(fallback)
+	case r.isTLS:
+		return NewTLSError(r.err)
+	case fallback != nil:
+		return NewDialError(fmt.Errorf("%w: %w", fallback, r.err))
+	default:
+		return NewDialError(r.err)
+	}
+}
+
+type recordingCredentials struct {
+	credentials.TransportCredentials
+	recorder *connectRecorder
+}
+
+func (c *recordingCredentials) ClientHandshake(
+	ctx context.Context,
+	authority string,
+	rawConn net.Conn,
+) (net.Conn, credentials.AuthInfo, error) {
+	conn, info, err := c.TransportCredentials.ClientHandshake(ctx, authority, rawConn)
+	if err != nil {
+		c.recorder.record(err, true)
+	}
+	return conn, info, err
+}
+
+func (c *recordingCredentials) Clone() credentials.TransportCredentials {
+	return &recordingCredentials{
+		TransportCredentials: c.TransportCredentials.Clone(),
+		recorder:             c.recorder,
+	}
+}
+
+// waitForReady fails on the first transient failure instead of letting gRPC
+// retry in the background, so Connect reports why the endpoint is unusable.
+func waitForReady(ctx context.Context, conn *grpc.ClientConn, recorder *connectRecorder) error {
+	conn.Connect()
+	for {
+		state := conn.GetState()
+		switch state {
+		case connectivity.Ready:
+			return nil
+		case connectivity.TransientFailure, connectivity.Shutdown:
+			return recorder.classify(nil)
+		}
+
+		if !conn.WaitForStateChange(ctx, state) {
+			return recorder.classify(fmt.Errorf("connection not ready (last state %s): %w", state, ctx.Err()))
+		}
+	}
+}
+
+func (b *GeyserGrpcBuilder) runConnectProbe(ctx context.Context, client *GeyserGrpcClient) error {
+	var err error
+	switch b.connectProbe {
+	case ConnectProbeVersion:
+		_, err = client.GetVersion(ctx)
+	case ConnectProbeHealth:
+		var response *grpc_health_v1.HealthCheckResponse
+		response, err = client.HealthCheck(ctx)
+		if err == nil && response.GetStatus() != grpc_health_v1.HealthCheckResponse_SERVING {
+			return NewProbeError(fmt.Errorf("service status %s", response.GetStatus()))
+		}
+	default:
+		return nil
+	}
+
+	if err == nil {
+		return nil
+	}
+
+	switch status.Code(err) {
+	case codes.Unauthenticated, codes.PermissionDenied:
+		return NewAuthenticationError(err)
+	default:
+		return NewProbeError(err)
+	}
+}
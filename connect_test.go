@@ -0,0 +1,133 @@
+package yellowstone
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	pb "github.com/andrew-solarstorm/yellowstone-grpc-client-go/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func closedAddress(t *testing.T) string {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	addr := lis.Addr().String()
+	lis.Close()
+	return addr
+}
+
+func expectBuilderError(t *testing.T, err error, errType string) {
+	t.Helper()
+
+	var builderErr *GeyserGrpcBuilderError
+	if !errors.As(err, &builderErr) {
+		t.Fatalf("Expected GeyserGrpcBuilderError, got %T: %v", err, err)
+	}
+	if builderErr.Type != errType {
+		t.Fatalf("Expected %s error type, got %s: %v", errType, builderErr.Type, err)
+	}
+}
+
+func TestConnectDialError(t *testing.T) {
+	_, err := BuildFromStatic("http://" + closedAddress(t)).Connect(context.Background())
+	expectBuilderError(t, err, "DialError")
+}
+
+func TestConnectContextDeadline(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	defer lis.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err = BuildFromStatic("http://" + lis.Addr().String()).Connect(ctx)
+	expectBuilderError(t, err, "DialError")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Connect did not honour context deadline, took %v", elapsed)
+	}
+}
+
+func TestConnectLazyDefersDial(t *testing.T) {
+	client, err := BuildFromStatic("http://" + closedAddress(t)).ConnectLazy()
+	if err != nil {
+		t.Fatalf("Expected ConnectLazy to succeed, got %v", err)
+	}
+	client.Close()
+}
+
+func TestConnectTLSError(t *testing.T) {
+	pki := newTestPKI(t)
+	addr := startTLSTestServer(t, pki, false)
+
+	_, err := BuildFromStatic("https://" + addr).Connect(context.Background())
+	expectBuilderError(t, err, "TLSError")
+
+	client, err := BuildFromStatic("https://" + addr).TLSCACert(pki.ca.certPEM).Connect(context.Background())
+	if err != nil {
+		t.Fatalf("Expected Connect to succeed with custom CA, got %v", err)
+	}
+	client.Close()
+}
+
+func TestConnectProbeAuthentication(t *testing.T) {
+	srv := &testGeyserServer{
+		getVersion: func(ctx context.Context) (*pb.GetVersionResponse, error) {
+			md, _ := metadata.FromIncomingContext(ctx)
+			if tokens := md.Get("x-token"); len(tokens) != 1 || tokens[0] != "secret" {
+				return nil, status.Error(codes.Unauthenticated, "invalid x-token")
+			}
+			return &pb.GetVersionResponse{Version: "test"}, nil
+		},
+	}
+	addr := startTestServer(t, srv)
+
+	_, err := BuildFromStatic("http://" + addr).
+		XToken("wrong").
+		ConnectProbe(ConnectProbeVersion).
+		Connect(context.Background())
+	expectBuilderError(t, err, "AuthenticationError")
+
+	client, err := BuildFromStatic("http://" + addr).
+		XToken("secret").
+		ConnectProbe(ConnectProbeVersion).
+		Connect(context.Background())
+	if err != nil {
+		t.Fatalf("Expected Connect to succeed with valid token, got %v", err)
+	}
+	client.Close()
+
+	_, err = BuildFromStatic("http://" + addr).
+		XToken("wrong").
+		Connect(context.Background())
+	if err != nil {
+		t.Fatalf("Expected Connect without probe to skip credential check, got %v", err)
+	}
+}
+
+func TestConnectProbeHealth(t *testing.T) {
+	addr := startTestServer(t, &testGeyserServer{})
+
+	client, err := BuildFromStatic("http://" + addr).
+		ConnectProbe(ConnectProbeHealth).
+		Connect(context.Background())
+	if err != nil {
+		t.Fatalf("Expected health probe to succeed, got %v", err)
+	}
+	client.Close()
+}
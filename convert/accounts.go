@@ -0,0 +1,150 @@
+package convert
+
+import (
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// Instruction is an outer or inner instruction with its program and accounts
+// resolved against the full account key list.
+type Instruction struct {
+	ProgramID solana.PublicKey
+	Accounts  solana.AccountMetaSlice
+	Data      []byte
+
+	// OuterIndex is the position of the top-level instruction this one belongs
+	// to. InnerIndex is its position among that instruction's inner calls, or
+	// -1 for the top-level instruction itself.
+	OuterIndex  int
+	InnerIndex  int
+	StackHeight *uint32
+}
+
+func (ix *Instruction) IsInner() bool {
+	return ix.InnerIndex >= 0
+}
+
+// AccountKeys returns the ordered account list instructions index into: the
+// static message keys followed by the writable and then the readonly
+// addresses loaded from lookup tables. Signer and writable flags follow the
+// message header; loaded addresses are never signers.
+func AccountKeys(tx *solana.Transaction, loaded LoadedAddresses) (solana.AccountMetaSlice, error) {
+	if tx == nil {
+		return nil, fmt.Errorf("transaction is nil")
+	}
+
+	message := &tx.Message
+	if message.IsVersioned() {
+		writable, readonly := lookupCounts(message)
+		if writable != len(loaded.Writable) || readonly != len(loaded.Readonly) {
+			return nil, fmt.Errorf(
+				"lookups reference %d writable and %d readonly addresses, meta has %d and %d",
+				writable, readonly, len(loaded.Writable), len(loaded.Readonly),
+			)
+		}
+	}
+
+	header := message.Header
+	static := len(message.AccountKeys)
+	signers := int(header.NumRequiredSignatures)
+	writableSigners := signers - int(header.NumReadonlySignedAccounts)
+	writableUnsigned := static - int(header.NumReadonlyUnsignedAccounts)
+	if signers > static || writableSigners < 0 || writableUnsigned < signers {
+		return nil, fmt.Errorf("message header does not match %d account keys", static)
+	}
+
+	keys := make(solana.AccountMetaSlice, 0, static+len(loaded.Writable)+len(loaded.Readonly))
+	for i, key := range message.AccountKeys {
+		keys = append(keys, &solana.AccountMeta{
+			PublicKey:  key,
+			IsSigner:   i < signers,
+			IsWritable: i < writableSigners || (i >= signers && i < writableUnsigned),
+		})
+	}
+	for _, key := range loaded.Writable {
+		keys = append(keys, &solana.AccountMeta{PublicKey: key, IsWritable: true})
+	}
+	for _, key := range loaded.Readonly {
+		keys = append(keys, &solana.AccountMeta{PublicKey: key})
+	}
+	return keys, nil
+}
+
+func lookupCounts(message *solana.Message) (writable, readonly int) {
+	for _, lookup := range message.AddressTableLookups {
+		writable += len(lookup.WritableIndexes)
+		readonly += len(lookup.ReadonlyIndexes)
+	}
+	return writable, readonly
+}
+
+// AccountKeys resolves the full account key list of the transaction, using
+// the loaded addresses from its meta.
+func (t *TransactionWithMeta) AccountKeys() (solana.AccountMetaSlice, error) {
+	var loaded LoadedAddresses
+	if t.Meta != nil {
+		loaded = t.Meta.LoadedAddresses
+	}
+	return AccountKeys(t.Transaction, loaded)
+}
+
+// Instructions returns every instruction in execution order, each outer
+// instruction followed by the inner instructions it invoked.
+func (t *TransactionWithMeta) Instructions() ([]Instruction, error) {
+	keys, err := t.AccountKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	inner := make(map[int][]InnerInstruction)
+	if t.Meta != nil {
+		for _, set := range t.Meta.InnerInstructions {
+			inner[int(set.Index)] = append(inner[int(set.Index)], set.Instructions...)
+		}
+	}
+
+	var result []Instruction
+	for i, ix := range t.Transaction.Message.Instructions {
+		outer, err := resolveInstruction(keys, ix)
+		if err != nil {
+			return nil, fmt.Errorf("instruction %d: %w", i, err)
+		}
+		outer.OuterIndex = i
+		outer.InnerIndex = -1
+		result = append(result, outer)
+
+		for j, innerIx := range inner[i] {
+			resolved, err := resolveInstruction(keys, innerIx.CompiledInstruction)
+			if err != nil {
+				return nil, fmt.Errorf("inner instruction %d.%d: %w", i, j, err)
+			}
+			resolved.OuterIndex = i
+			resolved.InnerIndex = j
+			resolved.StackHeight = innerIx.StackHeight
+			result = append(result, resolved)
+		}
+	}
+	return result, nil
+}
+
+func resolveInstruction(keys solana.AccountMetaSlice, ix solana.CompiledInstruction) (Instruction, error) {
+	if int(ix.ProgramIDIndex) >= len(keys) {
+		return Instruction{}, fmt.Errorf("program id index %d out of range of %d keys", ix.ProgramIDIndex, len(keys))
+	}
+
+	accounts := make(solana.AccountMetaSlice, len(ix.Accounts))
+	for i, index := range ix.Accounts {
+		if int(index) >= len(keys) {
+			return Instruction{}, fmt.Errorf("account index %d out of range of %d keys", index, len(keys))
+		}
+		meta := *keys[index]
+		accounts[i] = &meta
+	}
+
+	return Instruction{
+		ProgramID: keys[ix.ProgramIDIndex].PublicKey,
+		Accounts:  accounts,
+		Data:      ix.Data,
+	}, nil
+}
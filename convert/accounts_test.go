@@ -0,0 +1,101 @@
+package convert
+
+import (
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+func TestAccountKeysWithLookups(t *testing.T) {
+	tx := testTransaction(t, true)
+	loaded := LoadedAddresses{
+		Writable: solana.PublicKeySlice{solana.NewWallet().PublicKey()},
+		Readonly: solana.PublicKeySlice{solana.TokenProgramID},
+	}
+
+	keys, err := AccountKeys(tx, loaded)
+	if err != nil {
+		t.Fatalf("AccountKeys failed: %v", err)
+	}
+
+	expected := []struct {
+		key              solana.PublicKey
+		signer, writable bool
+	}{
+		{tx.Message.AccountKeys[0], true, true},
+		{tx.Message.AccountKeys[1], true, false},
+		{tx.Message.AccountKeys[2], false, true},
+		{tx.Message.AccountKeys[3], false, false},
+		{loaded.Writable[0], false, true},
+		{loaded.Readonly[0], false, false},
+	}
+	if len(keys) != len(expected) {
+		t.Fatalf("Expected %d keys, got %d", len(expected), len(keys))
+	}
+	for i, want := range expected {
+		got := keys[i]
+		if !got.PublicKey.Equals(want.key) || got.IsSigner != want.signer || got.IsWritable != want.writable {
+			t.Errorf("Key %d: expected %s signer=%v writable=%v, got %s signer=%v writable=%v",
+				i, want.key, want.signer, want.writable, got.PublicKey, got.IsSigner, got.IsWritable)
+		}
+	}
+
+	if _, err := AccountKeys(tx, LoadedAddresses{}); err == nil {
+		t.Error("Expected error when loaded addresses do not match lookups")
+	}
+}
+
+func TestInstructionsResolveLoadedAccounts(t *testing.T) {
+	tx := testTransaction(t, true)
+	writable := solana.NewWallet().PublicKey()
+	height := uint32(2)
+
+	result := &TransactionWithMeta{
+		Transaction: tx,
+		Meta: &TransactionMeta{
+			LoadedAddresses: LoadedAddresses{
+				Writable: solana.PublicKeySlice{writable},
+				Readonly: solana.PublicKeySlice{solana.TokenProgramID},
+			},
+			InnerInstructions: []InnerInstructions{{
+				Index: 1,
+				Instructions: []InnerInstruction{{
+					CompiledInstruction: solana.CompiledInstruction{ProgramIDIndex: 5, Accounts: []uint16{4, 0}},
+					StackHeight:         &height,
+				}},
+			}},
+		},
+	}
+
+	instructions, err := result.Instructions()
+	if err != nil {
+		t.Fatalf("Instructions failed: %v", err)
+	}
+	if len(instructions) != 3 {
+		t.Fatalf("Expected 3 instructions, got %d", len(instructions))
+	}
+
+	outer := instructions[1]
+	if outer.IsInner() || outer.OuterIndex != 1 || !outer.ProgramID.Equals(tx.Message.AccountKeys[3]) {
+		t.Errorf("Unexpected outer instruction: %+v", outer)
+	}
+	if !outer.Accounts[1].PublicKey.Equals(writable) || !outer.Accounts[1].IsWritable {
+		t.Errorf("Expected loaded writable account, got %+v", outer.Accounts[1])
+	}
+	if !outer.Accounts[2].PublicKey.Equals(solana.TokenProgramID) || outer.Accounts[2].IsWritable {
+		t.Errorf("Expected loaded readonly account, got %+v", outer.Accounts[2])
+	}
+
+	inner := instructions[2]
+	if !inner.IsInner() || inner.OuterIndex != 1 || inner.InnerIndex != 0 || !inner.ProgramID.Equals(solana.TokenProgramID) {
+		t.Errorf("Unexpected inner instruction: %+v", inner)
+	}
+	if inner.StackHeight == nil || *inner.StackHeight != 2 {
+		t.Errorf("Expected stack height 2, got %v", inner.StackHeight)
+	}
+
+	result.Meta.InnerInstructions[0].Instructions[0].Accounts = []uint16{9}
+	if _, err := result.Instructions(); err == nil {
+		t.Error("Expected error for out of range account index")
+	}
+}
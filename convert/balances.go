@@ -0,0 +1,155 @@
+package convert
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// TokenDelta is the net change of one mint held by one owner, summed over
+// all of the owner's token accounts touched by the transaction. Amounts are
+// in base units.
+type TokenDelta struct {
+	Owner     solana.PublicKey
+	Mint      solana.PublicKey
+	ProgramID solana.PublicKey
+	Decimals  uint8
+	Pre       *big.Int
+	Post      *big.Int
+	Delta     *big.Int
+}
+
+type LamportDelta struct {
+	Account solana.PublicKey
+	Pre     uint64
+	Post    uint64
+	Delta   int64
+}
+
+type BalanceChanges struct {
+	Tokens   []TokenDelta
+	Lamports []LamportDelta
+}
+
+// BalanceChanges returns the non-zero token and lamport changes of the
+// transaction.
+func (t *TransactionWithMeta) BalanceChanges() (*BalanceChanges, error) {
+	if t.Meta == nil {
+		return nil, fmt.Errorf("transaction has no meta")
+	}
+
+	metas, err := t.AccountKeys()
+	if err != nil {
+		return nil, err
+	}
+	keys := make(solana.PublicKeySlice, len(metas))
+	for i, meta := range metas {
+		keys[i] = meta.PublicKey
+	}
+
+	lamports, err := LamportDeltas(keys, t.Meta.PreBalances, t.Meta.PostBalances)
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := TokenDeltas(keys, t.Meta.PreTokenBalances, t.Meta.PostTokenBalances)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BalanceChanges{Tokens: tokens, Lamports: lamports}, nil
+}
+
+// LamportDeltas pairs PreBalances and PostBalances with the full account key
+// list and returns the accounts whose balance changed, in key order.
+func LamportDeltas(keys solana.PublicKeySlice, pre, post []uint64) ([]LamportDelta, error) {
+	if len(pre) != len(post) || len(pre) > len(keys) {
+		return nil, fmt.Errorf("balance lists of %d and %d entries do not match %d account keys", len(pre), len(post), len(keys))
+	}
+
+	var deltas []LamportDelta
+	for i := range pre {
+		if pre[i] == post[i] {
+			continue
+		}
+		deltas = append(deltas, LamportDelta{
+			Account: keys[i],
+			Pre:     pre[i],
+			Post:    post[i],
+			Delta:   int64(post[i]) - int64(pre[i]),
+		})
+	}
+	return deltas, nil
+}
+
+type ownerMint struct {
+	owner solana.PublicKey
+	mint  solana.PublicKey
+}
+
+// TokenDeltas pairs pre and post token balances by account index and sums
+// them per (owner, mint). An account present on one side only, because it was
+// created or closed, counts as zero on the other. When the node did not
+// record an owner, the token account itself is used. Deltas are returned in
+// order of first appearance and zero deltas are dropped.
+func TokenDeltas(keys solana.PublicKeySlice, pre, post []TokenBalance) ([]TokenDelta, error) {
+	var (
+		order []ownerMint
+		byKey = make(map[ownerMint]*TokenDelta)
+	)
+
+	add := func(balance TokenBalance, post bool) error {
+		if int(balance.AccountIndex) >= len(keys) {
+			return fmt.Errorf("token balance account index %d out of range of %d keys", balance.AccountIndex, len(keys))
+		}
+
+		owner := balance.Owner
+		if owner.IsZero() {
+			owner = keys[balance.AccountIndex]
+		}
+		key := ownerMint{owner: owner, mint: balance.Mint}
+
+		delta, ok := byKey[key]
+		if !ok {
+			delta = &TokenDelta{
+				Owner:     owner,
+				Mint:      balance.Mint,
+				ProgramID: balance.ProgramID,
+				Decimals:  balance.Decimals,
+				Pre:       new(big.Int),
+				Post:      new(big.Int),
+			}
+			byKey[key] = delta
+			order = append(order, key)
+		}
+
+		amount := new(big.Int).SetUint64(balance.Amount)
+		if post {
+			delta.Post.Add(delta.Post, amount)
+		} else {
+			delta.Pre.Add(delta.Pre, amount)
+		}
+		return nil
+	}
+
+	for _, balance := range pre {
+		if err := add(balance, false); err != nil {
+			return nil, err
+		}
+	}
+	for _, balance := range post {
+		if err := add(balance, true); err != nil {
+			return nil, err
+		}
+	}
+
+	var deltas []TokenDelta
+	for _, key := range order {
+		delta := byKey[key]
+		delta.Delta = new(big.Int).Sub(delta.Post, delta.Pre)
+		if delta.Delta.Sign() != 0 {
+			deltas = append(deltas, *delta)
+		}
+	}
+	return deltas, nil
+}
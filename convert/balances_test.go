@@ -0,0 +1,85 @@
+package convert
+
+import (
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+func TestTokenDeltas(t *testing.T) {
+	trader := solana.NewWallet().PublicKey()
+	pool := solana.NewWallet().PublicKey()
+	usdc := solana.MustPublicKeyFromBase58("EPjFWdd5AufqSSqeM2qRh3aVh2ZdZWbFTEjwXf2hrnQ1")
+	keys := solana.PublicKeySlice{
+		trader,
+		solana.NewWallet().PublicKey(), // trader USDC, closed
+		solana.NewWallet().PublicKey(), // trader wSOL, created
+		solana.NewWallet().PublicKey(), // pool USDC
+		solana.NewWallet().PublicKey(), // pool wSOL
+		solana.NewWallet().PublicKey(), // ownerless account, unchanged
+	}
+
+	pre := []TokenBalance{
+		{AccountIndex: 1, Owner: trader, Mint: usdc, Amount: 5_000_000, Decimals: 6},
+		{AccountIndex: 3, Owner: pool, Mint: usdc, Amount: 100_000_000, Decimals: 6},
+		{AccountIndex: 4, Owner: pool, Mint: solana.SolMint, Amount: 10_000_000_000, Decimals: 9},
+		{AccountIndex: 5, Mint: usdc, Amount: 1, Decimals: 6},
+	}
+	post := []TokenBalance{
+		{AccountIndex: 2, Owner: trader, Mint: solana.SolMint, Amount: 18446744073709551615, Decimals: 9},
+		{AccountIndex: 3, Owner: pool, Mint: usdc, Amount: 105_000_000, Decimals: 6},
+		{AccountIndex: 4, Owner: pool, Mint: solana.SolMint, Amount: 9_970_000_000, Decimals: 9},
+		{AccountIndex: 5, Mint: usdc, Amount: 1, Decimals: 6},
+	}
+
+	deltas, err := TokenDeltas(keys, pre, post)
+	if err != nil {
+		t.Fatalf("TokenDeltas failed: %v", err)
+	}
+
+	want := []struct {
+		owner, mint solana.PublicKey
+		delta       string
+		decimals    uint8
+	}{
+		{trader, usdc, "-5000000", 6},
+		{pool, usdc, "5000000", 6},
+		{pool, solana.SolMint, "-30000000", 9},
+		{trader, solana.SolMint, "18446744073709551615", 9},
+	}
+	if len(deltas) != len(want) {
+		t.Fatalf("Expected %d deltas, got %d: %+v", len(want), len(deltas), deltas)
+	}
+	for i, w := range want {
+		d := deltas[i]
+		if !d.Owner.Equals(w.owner) || !d.Mint.Equals(w.mint) || d.Delta.String() != w.delta || d.Decimals != w.decimals {
+			t.Errorf("Delta %d: expected %s/%s %s, got %s/%s %s", i, w.owner, w.mint, w.delta, d.Owner, d.Mint, d.Delta)
+		}
+	}
+
+	if _, err := TokenDeltas(keys[:2], pre, post); err == nil {
+		t.Error("Expected error for out of range account index")
+	}
+}
+
+func TestLamportDeltas(t *testing.T) {
+	keys := solana.PublicKeySlice{solana.NewWallet().PublicKey(), solana.NewWallet().PublicKey(), solana.SystemProgramID}
+
+	deltas, err := LamportDeltas(keys, []uint64{1_000_000, 0, 1}, []uint64{994_999, 5_001, 1})
+	if err != nil {
+		t.Fatalf("LamportDeltas failed: %v", err)
+	}
+	if len(deltas) != 2 {
+		t.Fatalf("Expected 2 deltas, got %d", len(deltas))
+	}
+	if !deltas[0].Account.Equals(keys[0]) || deltas[0].Delta != -5_001 {
+		t.Errorf("Unexpected payer delta: %+v", deltas[0])
+	}
+	if !deltas[1].Account.Equals(keys[1]) || deltas[1].Delta != 5_001 {
+		t.Errorf("Unexpected recipient delta: %+v", deltas[1])
+	}
+
+	if _, err := LamportDeltas(keys, []uint64{1}, []uint64{1, 2}); err == nil {
+		t.Error("Expected error for mismatched balance lists")
+	}
+}
@@ -0,0 +1,217 @@
+package convert
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	pb "github.com/andrew-solarstorm/yellowstone-grpc-client-go/proto"
+	"github.com/gagliardetto/solana-go"
+)
+
+func randomSignature(t *testing.T) solana.Signature {
+	t.Helper()
+	var sig solana.Signature
+	if _, err := rand.Read(sig[:]); err != nil {
+		t.Fatalf("rand failed: %v", err)
+	}
+	return sig
+}
+
+func testTransaction(t *testing.T, versioned bool) *solana.Transaction {
+	t.Helper()
+
+	payer := solana.NewWallet().PublicKey()
+	signer := solana.NewWallet().PublicKey()
+	program := solana.NewWallet().PublicKey()
+
+	message := solana.Message{
+		Header: solana.MessageHeader{
+			NumRequiredSignatures:       2,
+			NumReadonlySignedAccounts:   1,
+			NumReadonlyUnsignedAccounts: 1,
+		},
+		AccountKeys:     solana.PublicKeySlice{payer, signer, solana.SystemProgramID, program},
+		RecentBlockhash: solana.Hash(solana.NewWallet().PublicKey()),
+		Instructions: []solana.CompiledInstruction{
+			{ProgramIDIndex: 2, Accounts: []uint16{0, 1}, Data: []byte{2, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0}},
+			{ProgramIDIndex: 3, Accounts: []uint16{0, 4, 5}, Data: []byte{9}},
+		},
+	}
+	if versioned {
+		message.SetAddressTableLookups([]solana.MessageAddressTableLookup{{
+			AccountKey:      solana.NewWallet().PublicKey(),
+			WritableIndexes: solana.Uint8SliceAsNum{3},
+			ReadonlyIndexes: solana.Uint8SliceAsNum{7},
+		}})
+	}
+
+	return &solana.Transaction{
+		Signatures: []solana.Signature{randomSignature(t), randomSignature(t)},
+		Message:    message,
+	}
+}
+
+// toProto builds the protobuf form of a wire-format transaction the way the
+// Geyser plugin does, straight from the decoded fields.
+func toProto(t *testing.T, wire []byte) *pb.Transaction {
+	t.Helper()
+
+	tx, err := solana.TransactionFromBytes(wire)
+	if err != nil {
+		t.Fatalf("TransactionFromBytes failed: %v", err)
+	}
+
+	message := &pb.Message{
+		Header: &pb.MessageHeader{
+			NumRequiredSignatures:       uint32(tx.Message.Header.NumRequiredSignatures),
+			NumReadonlySignedAccounts:   uint32(tx.Message.Header.NumReadonlySignedAccounts),
+			NumReadonlyUnsignedAccounts: uint32(tx.Message.Header.NumReadonlyUnsignedAccounts),
+		},
+		RecentBlockhash: tx.Message.RecentBlockhash[:],
+		Versioned:       tx.Message.IsVersioned(),
+	}
+	for _, key := range tx.Message.AccountKeys {
+		message.AccountKeys = append(message.AccountKeys, key.Bytes())
+	}
+	for _, ix := range tx.Message.Instructions {
+		accounts := make([]byte, len(ix.Accounts))
+		for i, index := range ix.Accounts {
+			accounts[i] = byte(index)
+		}
+		message.Instructions = append(message.Instructions, &pb.CompiledInstruction{
+			ProgramIdIndex: uint32(ix.ProgramIDIndex),
+			Accounts:       accounts,
+			Data:           ix.Data,
+		})
+	}
+	for _, lookup := range tx.Message.AddressTableLookups {
+		message.AddressTableLookups = append(message.AddressTableLookups, &pb.MessageAddressTableLookup{
+			AccountKey:      lookup.AccountKey.Bytes(),
+			WritableIndexes: lookup.WritableIndexes,
+			ReadonlyIndexes: lookup.ReadonlyIndexes,
+		})
+	}
+
+	result := &pb.Transaction{Message: message}
+	for _, sig := range tx.Signatures {
+		result.Signatures = append(result.Signatures, sig[:])
+	}
+	return result
+}
+
+func TestTransactionRoundTrip(t *testing.T) {
+	for _, versioned := range []bool{false, true} {
+		name := "legacy"
+		if versioned {
+			name = "v0"
+		}
+		t.Run(name, func(t *testing.T) {
+			wire, err := testTransaction(t, versioned).MarshalBinary()
+			if err != nil {
+				t.Fatalf("MarshalBinary failed: %v", err)
+			}
+
+			tx, err := Transaction(toProto(t, wire))
+			if err != nil {
+				t.Fatalf("Transaction failed: %v", err)
+			}
+
+			if tx.Message.IsVersioned() != versioned {
+				t.Errorf("Expected versioned=%v", versioned)
+			}
+			if versioned && len(tx.Message.AddressTableLookups) != 1 {
+				t.Errorf("Expected 1 address table lookup, got %d", len(tx.Message.AddressTableLookups))
+			}
+
+			got, err := tx.MarshalBinary()
+			if err != nil {
+				t.Fatalf("MarshalBinary of converted transaction failed: %v", err)
+			}
+			if !bytes.Equal(got, wire) {
+				t.Errorf("Converted transaction does not match wire format:\n got %x\nwant %x", got, wire)
+			}
+		})
+	}
+}
+
+func TestTransactionInfo(t *testing.T) {
+	wire, err := testTransaction(t, true).MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary failed: %v", err)
+	}
+	ptx := toProto(t, wire)
+
+	loaded := solana.NewWallet().PublicKey()
+	height := uint32(2)
+	units := uint64(1234)
+	info := &pb.SubscribeUpdateTransactionInfo{
+		Signature:   ptx.Signatures[0],
+		Index:       7,
+		Transaction: ptx,
+		Meta: &pb.TransactionStatusMeta{
+			Err:          &pb.TransactionError{Err: []byte{8, 0, 0, 0}},
+			Fee:          5000,
+			PreBalances:  []uint64{10, 20},
+			PostBalances: []uint64{5, 20},
+			InnerInstructions: []*pb.InnerInstructions{{
+				Index: 1,
+				Instructions: []*pb.InnerInstruction{
+					{ProgramIdIndex: 2, Accounts: []byte{0, 1}, Data: []byte{1}, StackHeight: &height},
+				},
+			}},
+			LogMessages: []string{"Program log: hi"},
+			PostTokenBalances: []*pb.TokenBalance{{
+				AccountIndex:  1,
+				Mint:          solana.SolMint.String(),
+				Owner:         loaded.String(),
+				UiTokenAmount: &pb.UiTokenAmount{Amount: "18446744073709551615", Decimals: 9},
+			}},
+			Rewards:                 []*pb.Reward{{Pubkey: loaded.String(), Lamports: -5, Commission: "10"}},
+			LoadedWritableAddresses: [][]byte{loaded.Bytes()},
+			ComputeUnitsConsumed:    &units,
+		},
+	}
+
+	result, err := TransactionInfo(info)
+	if err != nil {
+		t.Fatalf("TransactionInfo failed: %v", err)
+	}
+
+	if result.Signature != result.Transaction.Signatures[0] || result.Index != 7 {
+		t.Errorf("Unexpected signature or index: %s %d", result.Signature, result.Index)
+	}
+
+	meta := result.Meta
+	if !bytes.Equal(meta.Err, []byte{8, 0, 0, 0}) || meta.Fee != 5000 {
+		t.Errorf("Unexpected err or fee: %x %d", meta.Err, meta.Fee)
+	}
+	if h := meta.InnerInstructions[0].Instructions[0].StackHeight; h == nil || *h != 2 {
+		t.Errorf("Expected stack height 2, got %v", h)
+	}
+	if balance := meta.PostTokenBalances[0]; balance.Amount != 18446744073709551615 || balance.Decimals != 9 || !balance.Owner.Equals(loaded) {
+		t.Errorf("Unexpected token balance: %+v", balance)
+	}
+	if c := meta.Rewards[0].Commission; c == nil || *c != 10 {
+		t.Errorf("Expected commission 10, got %v", c)
+	}
+	if len(meta.LoadedAddresses.Writable) != 1 || !meta.LoadedAddresses.Writable[0].Equals(loaded) {
+		t.Errorf("Unexpected loaded addresses: %v", meta.LoadedAddresses)
+	}
+	if meta.ComputeUnitsConsumed == nil || *meta.ComputeUnitsConsumed != 1234 {
+		t.Errorf("Unexpected compute units: %v", meta.ComputeUnitsConsumed)
+	}
+}
+
+func TestTransactionRejectsMalformedKeys(t *testing.T) {
+	wire, err := testTransaction(t, false).MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary failed: %v", err)
+	}
+	ptx := toProto(t, wire)
+	ptx.Message.AccountKeys[1] = []byte{1, 2, 3}
+
+	if _, err := Transaction(ptx); err == nil {
+		t.Error("Expected error for malformed account key")
+	}
+}
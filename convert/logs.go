@@ -0,0 +1,197 @@
+package convert
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// Invocation is one program invocation reconstructed from LogMessages.
+type Invocation struct {
+	ProgramID solana.PublicKey
+	Depth     int
+	Parent    *Invocation
+	Children  []*Invocation
+
+	// Logs holds "Program log:" messages without the prefix.
+	Logs []string
+	// Data holds one payload per "Program data:" line, such as an Anchor
+	// event. Lines carrying several base64 slices are concatenated.
+	Data       [][]byte
+	ReturnData []byte
+
+	ComputeConsumed uint64
+	ComputeLimit    uint64
+
+	// Complete reports that the result line was seen. Success and Err are
+	// only meaningful when it is set.
+	Complete bool
+	Success  bool
+	Err      string
+
+	// Lines holds every raw line emitted by this invocation itself, from its
+	// invoke line to its result line, without those of its children.
+	Lines []string
+}
+
+type ProgramLogs struct {
+	Invocations []*Invocation
+	// Truncated reports that the node cut the log short, so later
+	// invocations and results are missing.
+	Truncated bool
+}
+
+// Walk visits all invocations in execution order until fn returns false.
+func (l *ProgramLogs) Walk(fn func(*Invocation) bool) {
+	for _, inv := range l.Invocations {
+		if !inv.walk(fn) {
+			return
+		}
+	}
+}
+
+func (inv *Invocation) walk(fn func(*Invocation) bool) bool {
+	if !fn(inv) {
+		return false
+	}
+	for _, child := range inv.Children {
+		if !child.walk(fn) {
+			return false
+		}
+	}
+	return true
+}
+
+func ParseLogs(lines []string) *ProgramLogs {
+	result := &ProgramLogs{}
+	var stack []*Invocation
+
+	for _, line := range lines {
+		if line == "Log truncated" {
+			result.Truncated = true
+			continue
+		}
+
+		if program, depth, ok := parseInvoke(line); ok {
+			inv := &Invocation{ProgramID: program, Depth: depth}
+			if len(stack) == 0 {
+				result.Invocations = append(result.Invocations, inv)
+			} else {
+				parent := stack[len(stack)-1]
+				inv.Parent = parent
+				parent.Children = append(parent.Children, inv)
+			}
+			inv.Lines = append(inv.Lines, line)
+			stack = append(stack, inv)
+			continue
+		}
+
+		if len(stack) == 0 {
+			continue
+		}
+		current := stack[len(stack)-1]
+		current.Lines = append(current.Lines, line)
+
+		if current.parseResult(line) {
+			stack = stack[:len(stack)-1]
+			continue
+		}
+		current.parseLine(line)
+	}
+
+	if len(stack) > 0 {
+		result.Truncated = true
+	}
+	return result
+}
+
+func parseInvoke(line string) (solana.PublicKey, int, bool) {
+	program, ok := invokedProgram(line)
+	if !ok {
+		return solana.PublicKey{}, 0, false
+	}
+	key, err := solana.PublicKeyFromBase58(program)
+	if err != nil {
+		return solana.PublicKey{}, 0, false
+	}
+
+	rest := strings.TrimPrefix(line, "Program "+program+" invoke [")
+	depth, err := strconv.Atoi(strings.TrimSuffix(rest, "]"))
+	if err != nil {
+		return solana.PublicKey{}, 0, false
+	}
+	return key, depth, true
+}
+
+func invokedProgram(line string) (string, bool) {
+	rest, ok := strings.CutPrefix(line, "Program ")
+	if !ok {
+		return "", false
+	}
+	program, rest, ok := strings.Cut(rest, " ")
+	if !ok || !strings.HasPrefix(rest, "invoke [") {
+		return "", false
+	}
+	return program, true
+}
+
+// parseResult handles the success and failure lines that close the
+// invocation.
+func (inv *Invocation) parseResult(line string) bool {
+	rest, ok := strings.CutPrefix(line, "Program "+inv.ProgramID.String()+" ")
+	if !ok {
+		return false
+	}
+
+	switch {
+	case rest == "success":
+		inv.Complete = true
+		inv.Success = true
+		return true
+	case strings.HasPrefix(rest, "failed: "):
+		inv.Complete = true
+		inv.Err = strings.TrimPrefix(rest, "failed: ")
+		return true
+	}
+	return false
+}
+
+func (inv *Invocation) parseLine(line string) {
+	switch {
+	case strings.HasPrefix(line, "Program log: "):
+		inv.Logs = append(inv.Logs, strings.TrimPrefix(line, "Program log: "))
+
+	case strings.HasPrefix(line, "Program data: "):
+		var payload []byte
+		for _, field := range strings.Fields(strings.TrimPrefix(line, "Program data: ")) {
+			chunk, err := base64.StdEncoding.DecodeString(field)
+			if err != nil {
+				return
+			}
+			payload = append(payload, chunk...)
+		}
+		inv.Data = append(inv.Data, payload)
+
+	case strings.HasPrefix(line, "Program return: "):
+		fields := strings.Fields(strings.TrimPrefix(line, "Program return: "))
+		if len(fields) == 2 {
+			if data, err := base64.StdEncoding.DecodeString(fields[1]); err == nil {
+				inv.ReturnData = data
+			}
+		}
+
+	default:
+		rest, ok := strings.CutPrefix(line, "Program "+inv.ProgramID.String()+" consumed ")
+		if !ok {
+			return
+		}
+		var consumed, limit uint64
+		if _, err := fmt.Sscanf(rest, "%d of %d compute units", &consumed, &limit); err == nil {
+			inv.ComputeConsumed = consumed
+			inv.ComputeLimit = limit
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package convert
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+func TestParseLogs(t *testing.T) {
+	program := solana.NewWallet().PublicKey().String()
+	token := solana.TokenProgramID.String()
+	event := []byte{0xe4, 0x45, 0xa5, 0x2e, 1, 2, 3}
+
+	logs := []string{
+		"Program " + program + " invoke [1]",
+		"Program log: Instruction: Swap",
+		"Program " + token + " invoke [2]",
+		"Program log: Instruction: Transfer",
+		"Program " + token + " consumed 4645 of 180000 compute units",
+		"Program " + token + " success",
+		"Program data: " + base64.StdEncoding.EncodeToString(event),
+		"Program data: " + base64.StdEncoding.EncodeToString([]byte{1}) + " " + base64.StdEncoding.EncodeToString([]byte{2}),
+		"Program return: " + program + " " + base64.StdEncoding.EncodeToString([]byte{42}),
+		"Program " + program + " consumed 25000 of 200000 compute units",
+		"Program " + program + " failed: custom program error: 0x1771",
+	}
+
+	parsed := ParseLogs(logs)
+	if parsed.Truncated {
+		t.Error("Expected complete logs")
+	}
+	if len(parsed.Invocations) != 1 {
+		t.Fatalf("Expected 1 top-level invocation, got %d", len(parsed.Invocations))
+	}
+
+	root := parsed.Invocations[0]
+	if root.ProgramID.String() != program || root.Depth != 1 {
+		t.Errorf("Unexpected root: %s depth %d", root.ProgramID, root.Depth)
+	}
+	if !root.Complete || root.Success || root.Err != "custom program error: 0x1771" {
+		t.Errorf("Unexpected result: complete=%v success=%v err=%q", root.Complete, root.Success, root.Err)
+	}
+	if root.ComputeConsumed != 25000 || root.ComputeLimit != 200000 {
+		t.Errorf("Unexpected compute: %d of %d", root.ComputeConsumed, root.ComputeLimit)
+	}
+	if len(root.Logs) != 1 || root.Logs[0] != "Instruction: Swap" {
+		t.Errorf("Unexpected logs: %q", root.Logs)
+	}
+	if len(root.Data) != 2 || !bytes.Equal(root.Data[0], event) || !bytes.Equal(root.Data[1], []byte{1, 2}) {
+		t.Errorf("Unexpected data: %x", root.Data)
+	}
+	if !bytes.Equal(root.ReturnData, []byte{42}) {
+		t.Errorf("Unexpected return data: %x", root.ReturnData)
+	}
+
+	if len(root.Children) != 1 {
+		t.Fatalf("Expected 1 child, got %d", len(root.Children))
+	}
+	child := root.Children[0]
+	if !child.ProgramID.Equals(solana.TokenProgramID) || child.Depth != 2 || child.Parent != root {
+		t.Errorf("Unexpected child: %s depth %d", child.ProgramID, child.Depth)
+	}
+	if !child.Success || child.ComputeConsumed != 4645 || len(child.Lines) != 4 {
+		t.Errorf("Unexpected child state: success=%v consumed=%d lines=%d", child.Success, child.ComputeConsumed, len(child.Lines))
+	}
+}
+
+func TestParseLogsTruncated(t *testing.T) {
+	program := solana.NewWallet().PublicKey().String()
+
+	explicit := ParseLogs([]string{
+		"Program " + program + " invoke [1]",
+		"Log truncated",
+	})
+	if !explicit.Truncated {
+		t.Error("Expected truncation from marker line")
+	}
+
+	open := ParseLogs([]string{
+		"Program " + program + " invoke [1]",
+		"Program log: partial",
+	})
+	if !open.Truncated || open.Invocations[0].Complete {
+		t.Error("Expected unterminated invocation to be reported as truncated")
+	}
+}
@@ -0,0 +1,214 @@
+package convert
+
+import (
+	"fmt"
+	"strconv"
+
+	pb "github.com/andrew-solarstorm/yellowstone-grpc-client-go/proto"
+	"github.com/gagliardetto/solana-go"
+)
+
+type TransactionMeta struct {
+	// Err is the bincode-encoded TransactionError, nil when the transaction
+	// succeeded.
+	Err               []byte
+	Fee               uint64
+	PreBalances       []uint64
+	PostBalances      []uint64
+	InnerInstructions []InnerInstructions
+	LogMessages       []string
+	PreTokenBalances  []TokenBalance
+	PostTokenBalances []TokenBalance
+	Rewards           []Reward
+	LoadedAddresses   LoadedAddresses
+	ReturnData        *ReturnData
+
+	// The *None flags report that the node did not record the field, as
+	// opposed to recording it empty.
+	InnerInstructionsNone bool
+	LogMessagesNone       bool
+
+	ComputeUnitsConsumed *uint64
+	CostUnits            *uint64
+}
+
+type InnerInstructions struct {
+	// Index is the position of the outer instruction that made these calls.
+	Index        uint16
+	Instructions []InnerInstruction
+}
+
+type InnerInstruction struct {
+	solana.CompiledInstruction
+	// StackHeight is nil for transactions executed before it was recorded.
+	StackHeight *uint32
+}
+
+type TokenBalance struct {
+	AccountIndex uint16
+	Mint         solana.PublicKey
+	Owner        solana.PublicKey
+	ProgramID    solana.PublicKey
+	Amount       uint64
+	Decimals     uint8
+}
+
+type Reward struct {
+	Pubkey      solana.PublicKey
+	Lamports    int64
+	PostBalance uint64
+	RewardType  pb.RewardType
+	// Commission is nil when the reward carries none.
+	Commission *uint8
+}
+
+type LoadedAddresses struct {
+	Writable solana.PublicKeySlice
+	Readonly solana.PublicKeySlice
+}
+
+type ReturnData struct {
+	ProgramID solana.PublicKey
+	Data      []byte
+}
+
+func Meta(meta *pb.TransactionStatusMeta) (*TransactionMeta, error) {
+	if meta == nil {
+		return nil, fmt.Errorf("transaction meta is nil")
+	}
+
+	result := &TransactionMeta{
+		Fee:                   meta.Fee,
+		PreBalances:           append([]uint64{}, meta.PreBalances...),
+		PostBalances:          append([]uint64{}, meta.PostBalances...),
+		LogMessages:           append([]string{}, meta.LogMessages...),
+		InnerInstructionsNone: meta.InnerInstructionsNone,
+		LogMessagesNone:       meta.LogMessagesNone,
+		ComputeUnitsConsumed:  cloneUint64(meta.ComputeUnitsConsumed),
+		CostUnits:             cloneUint64(meta.CostUnits),
+	}
+
+	if meta.Err != nil {
+		result.Err = cloneBytes(meta.Err.Err)
+	}
+
+	result.InnerInstructions = make([]InnerInstructions, len(meta.InnerInstructions))
+	for i, inner := range meta.InnerInstructions {
+		instructions := make([]InnerInstruction, len(inner.GetInstructions()))
+		for j, ix := range inner.GetInstructions() {
+			instructions[j] = InnerInstruction{
+				CompiledInstruction: CompiledInstruction(ix.GetProgramIdIndex(), ix.GetAccounts(), ix.GetData()),
+			}
+			if ix.StackHeight != nil {
+				height := *ix.StackHeight
+				instructions[j].StackHeight = &height
+			}
+		}
+		result.InnerInstructions[i] = InnerInstructions{
+			Index:        uint16(inner.GetIndex()),
+			Instructions: instructions,
+		}
+	}
+
+	var err error
+	if result.PreTokenBalances, err = tokenBalances(meta.PreTokenBalances); err != nil {
+		return nil, fmt.Errorf("pre token balances: %w", err)
+	}
+	if result.PostTokenBalances, err = tokenBalances(meta.PostTokenBalances); err != nil {
+		return nil, fmt.Errorf("post token balances: %w", err)
+	}
+
+	result.Rewards = make([]Reward, len(meta.Rewards))
+	for i, reward := range meta.Rewards {
+		if result.Rewards[i], err = rewardFromProto(reward); err != nil {
+			return nil, fmt.Errorf("reward %d: %w", i, err)
+		}
+	}
+
+	if result.LoadedAddresses.Writable, err = PublicKeys(meta.LoadedWritableAddresses); err != nil {
+		return nil, fmt.Errorf("loaded writable addresses: %w", err)
+	}
+	if result.LoadedAddresses.Readonly, err = PublicKeys(meta.LoadedReadonlyAddresses); err != nil {
+		return nil, fmt.Errorf("loaded readonly addresses: %w", err)
+	}
+
+	if meta.ReturnData != nil && !meta.ReturnDataNone {
+		programID, err := PublicKey(meta.ReturnData.ProgramId)
+		if err != nil {
+			return nil, fmt.Errorf("return data: %w", err)
+		}
+		result.ReturnData = &ReturnData{
+			ProgramID: programID,
+			Data:      cloneBytes(meta.ReturnData.Data),
+		}
+	}
+
+	return result, nil
+}
+
+func tokenBalances(balances []*pb.TokenBalance) ([]TokenBalance, error) {
+	result := make([]TokenBalance, len(balances))
+	for i, balance := range balances {
+		var err error
+		result[i] = TokenBalance{AccountIndex: uint16(balance.GetAccountIndex())}
+
+		if result[i].Mint, err = optionalPublicKey(balance.GetMint()); err != nil {
+			return nil, fmt.Errorf("balance %d mint: %w", i, err)
+		}
+		if result[i].Owner, err = optionalPublicKey(balance.GetOwner()); err != nil {
+			return nil, fmt.Errorf("balance %d owner: %w", i, err)
+		}
+		if result[i].ProgramID, err = optionalPublicKey(balance.GetProgramId()); err != nil {
+			return nil, fmt.Errorf("balance %d program id: %w", i, err)
+		}
+
+		amount := balance.GetUiTokenAmount()
+		result[i].Decimals = uint8(amount.GetDecimals())
+		if amount.GetAmount() != "" {
+			if result[i].Amount, err = strconv.ParseUint(amount.GetAmount(), 10, 64); err != nil {
+				return nil, fmt.Errorf("balance %d amount: %w", i, err)
+			}
+		}
+	}
+	return result, nil
+}
+
+func rewardFromProto(reward *pb.Reward) (Reward, error) {
+	pubkey, err := optionalPublicKey(reward.GetPubkey())
+	if err != nil {
+		return Reward{}, err
+	}
+
+	result := Reward{
+		Pubkey:      pubkey,
+		Lamports:    reward.GetLamports(),
+		PostBalance: reward.GetPostBalance(),
+		RewardType:  reward.GetRewardType(),
+	}
+
+	if commission := reward.GetCommission(); commission != "" {
+		value, err := strconv.ParseUint(commission, 10, 8)
+		if err != nil {
+			return Reward{}, fmt.Errorf("commission: %w", err)
+		}
+		c := uint8(value)
+		result.Commission = &c
+	}
+
+	return result, nil
+}
+
+func optionalPublicKey(s string) (solana.PublicKey, error) {
+	if s == "" {
+		return solana.PublicKey{}, nil
+	}
+	return solana.PublicKeyFromBase58(s)
+}
+
+func cloneUint64(v *uint64) *uint64 {
+	if v == nil {
+		return nil
+	}
+	value := *v
+	return &value
+}
@@ -0,0 +1,171 @@
+// Package convert turns the raw protobuf transaction types streamed by
+// Yellowstone into solana-go types.
+package convert
+
+import (
+	"fmt"
+
+	pb "github.com/andrew-solarstorm/yellowstone-grpc-client-go/proto"
+	"github.com/gagliardetto/solana-go"
+)
+
+// TransactionWithMeta is a decoded SubscribeUpdateTransactionInfo.
+type TransactionWithMeta struct {
+	Signature   solana.Signature
+	IsVote      bool
+	Index       uint64
+	Transaction *solana.Transaction
+	// Meta is nil when the update carries no status meta.
+	Meta *TransactionMeta
+}
+
+func TransactionInfo(info *pb.SubscribeUpdateTransactionInfo) (*TransactionWithMeta, error) {
+	if info == nil {
+		return nil, fmt.Errorf("transaction info is nil")
+	}
+
+	signature, err := Signature(info.Signature)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := Transaction(info.Transaction)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &TransactionWithMeta{
+		Signature:   signature,
+		IsVote:      info.IsVote,
+		Index:       info.Index,
+		Transaction: tx,
+	}
+
+	if info.Meta != nil {
+		result.Meta, err = Meta(info.Meta)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+func Transaction(tx *pb.Transaction) (*solana.Transaction, error) {
+	if tx == nil {
+		return nil, fmt.Errorf("transaction is nil")
+	}
+
+	message, err := Message(tx.Message)
+	if err != nil {
+		return nil, err
+	}
+
+	signatures := make([]solana.Signature, len(tx.Signatures))
+	for i, raw := range tx.Signatures {
+		signatures[i], err = Signature(raw)
+		if err != nil {
+			return nil, fmt.Errorf("signature %d: %w", i, err)
+		}
+	}
+
+	return &solana.Transaction{
+		Signatures: signatures,
+		Message:    message,
+	}, nil
+}
+
+func Message(msg *pb.Message) (solana.Message, error) {
+	var message solana.Message
+	if msg == nil {
+		return message, fmt.Errorf("message is nil")
+	}
+
+	header := msg.GetHeader()
+	message.Header = solana.MessageHeader{
+		NumRequiredSignatures:       uint8(header.GetNumRequiredSignatures()),
+		NumReadonlySignedAccounts:   uint8(header.GetNumReadonlySignedAccounts()),
+		NumReadonlyUnsignedAccounts: uint8(header.GetNumReadonlyUnsignedAccounts()),
+	}
+
+	keys, err := PublicKeys(msg.AccountKeys)
+	if err != nil {
+		return message, fmt.Errorf("account keys: %w", err)
+	}
+	message.AccountKeys = keys
+
+	if len(msg.RecentBlockhash) != 32 {
+		return message, fmt.Errorf("invalid recent blockhash length %d", len(msg.RecentBlockhash))
+	}
+	message.RecentBlockhash = solana.HashFromBytes(msg.RecentBlockhash)
+
+	message.Instructions = make([]solana.CompiledInstruction, len(msg.Instructions))
+	for i, ix := range msg.Instructions {
+		message.Instructions[i] = CompiledInstruction(ix.GetProgramIdIndex(), ix.GetAccounts(), ix.GetData())
+	}
+
+	if msg.Versioned {
+		message.SetVersion(solana.MessageVersionV0)
+
+		lookups := make([]solana.MessageAddressTableLookup, len(msg.AddressTableLookups))
+		for i, lookup := range msg.AddressTableLookups {
+			key, err := PublicKey(lookup.GetAccountKey())
+			if err != nil {
+				return message, fmt.Errorf("address table lookup %d: %w", i, err)
+			}
+			lookups[i] = solana.MessageAddressTableLookup{
+				AccountKey:      key,
+				WritableIndexes: solana.Uint8SliceAsNum(cloneBytes(lookup.GetWritableIndexes())),
+				ReadonlyIndexes: solana.Uint8SliceAsNum(cloneBytes(lookup.GetReadonlyIndexes())),
+			}
+		}
+		message.SetAddressTableLookups(lookups)
+	}
+
+	return message, nil
+}
+
+func CompiledInstruction(programIDIndex uint32, accounts, data []byte) solana.CompiledInstruction {
+	indexes := make([]uint16, len(accounts))
+	for i, index := range accounts {
+		indexes[i] = uint16(index)
+	}
+	return solana.CompiledInstruction{
+		ProgramIDIndex: uint16(programIDIndex),
+		Accounts:       indexes,
+		Data:           cloneBytes(data),
+	}
+}
+
+func PublicKey(raw []byte) (solana.PublicKey, error) {
+	if len(raw) != solana.PublicKeyLength {
+		return solana.PublicKey{}, fmt.Errorf("invalid public key length %d", len(raw))
+	}
+	return solana.PublicKeyFromBytes(raw), nil
+}
+
+func PublicKeys(raw [][]byte) (solana.PublicKeySlice, error) {
+	keys := make(solana.PublicKeySlice, len(raw))
+	for i, key := range raw {
+		var err error
+		keys[i], err = PublicKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("key %d: %w", i, err)
+		}
+	}
+	return keys, nil
+}
+
+func Signature(raw []byte) (solana.Signature, error) {
+	if len(raw) != solana.SignatureLength {
+		return solana.Signature{}, fmt.Errorf("invalid signature length %d", len(raw))
+	}
+	return solana.SignatureFromBytes(raw), nil
+}
+
+func cloneBytes(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	return append([]byte{}, b...)
+}
@@ -0,0 +1,131 @@
+package convert
+
+import "github.com/gagliardetto/solana-go"
+
+// InstructionNode is one invocation in the call tree of a transaction.
+// Top-level instructions have depth 1; a CPI made at depth n has depth n+1.
+type InstructionNode struct {
+	Instruction
+	Depth    int
+	Parent   *InstructionNode
+	Children []*InstructionNode
+	// Logs holds the log lines emitted by this invocation itself, from its
+	// invoke line to its result line, without those of its children.
+	Logs []string
+	// Invocation is the same part of the log parsed, nil when the log was
+	// truncated before this instruction ran.
+	Invocation *Invocation
+}
+
+// Walk visits the node and its descendants in execution order until fn
+// returns false.
+func (n *InstructionNode) Walk(fn func(*InstructionNode) bool) bool {
+	if !fn(n) {
+		return false
+	}
+	for _, child := range n.Children {
+		if !child.Walk(fn) {
+			return false
+		}
+	}
+	return true
+}
+
+// InstructionTree returns the top-level instructions of the transaction with
+// their inner instructions nested by stack height and parsed logs attached.
+func (t *TransactionWithMeta) InstructionTree() ([]*InstructionNode, error) {
+	instructions, err := t.Instructions()
+	if err != nil {
+		return nil, err
+	}
+
+	var logs []string
+	if t.Meta != nil {
+		logs = t.Meta.LogMessages
+	}
+	return BuildInstructionTree(instructions, logs), nil
+}
+
+// BuildInstructionTree nests instructions as returned by Instructions. Inner
+// instructions without a stack height, recorded before Solana v1.14.6, are
+// attached directly to their top-level instruction.
+func BuildInstructionTree(instructions []Instruction, logs []string) []*InstructionNode {
+	var (
+		roots []*InstructionNode
+		stack []*InstructionNode
+	)
+
+	for _, ix := range instructions {
+		node := &InstructionNode{Instruction: ix}
+
+		if !ix.IsInner() {
+			node.Depth = 1
+			roots = append(roots, node)
+			stack = append(stack[:0], node)
+			continue
+		}
+		if len(stack) == 0 {
+			continue
+		}
+
+		depth := 2
+		if ix.StackHeight != nil && *ix.StackHeight > 1 {
+			depth = int(*ix.StackHeight)
+		}
+		for len(stack) > 1 && stack[len(stack)-1].Depth >= depth {
+			stack = stack[:len(stack)-1]
+		}
+
+		parent := stack[len(stack)-1]
+		node.Depth = depth
+		node.Parent = parent
+		parent.Children = append(parent.Children, node)
+		stack = append(stack, node)
+	}
+
+	linkLogs(roots, logs)
+	return roots
+}
+
+// linkLogs assigns log lines and parsed invocations to nodes, which appear
+// in the log in the same order as the instructions execute. Precompiles such
+// as Ed25519 and Secp256k1 write no invoke line, so nodes without a matching
+// invocation are skipped and linking resumes at the next match.
+func linkLogs(roots []*InstructionNode, logs []string) {
+	var order []*InstructionNode
+	for _, root := range roots {
+		root.Walk(func(n *InstructionNode) bool {
+			order = append(order, n)
+			return true
+		})
+	}
+
+	next := 0
+	ParseLogs(logs).Walk(func(inv *Invocation) bool {
+		for i := next; i < len(order); i++ {
+			node := order[i]
+			if node.ProgramID.Equals(inv.ProgramID) && node.Depth == inv.Depth {
+				node.Logs = inv.Lines
+				node.Invocation = inv
+				next = i + 1
+				break
+			}
+		}
+		return next < len(order)
+	})
+}
+
+// FindCPIs returns every invocation of program made through a CPI, that is
+// below the top level, in execution order.
+func FindCPIs(roots []*InstructionNode, program solana.PublicKey) []*InstructionNode {
+	var found []*InstructionNode
+	for _, root := range roots {
+		root.Walk(func(n *InstructionNode) bool {
+			if n.Depth > 1 && n.ProgramID.Equals(program) {
+				found = append(found, n)
+			}
+			return true
+		})
+	}
+	return found
+}
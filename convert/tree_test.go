@@ -0,0 +1,105 @@
+package convert
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+func TestBuildInstructionTree(t *testing.T) {
+	a, b, c, d, e := solana.NewWallet().PublicKey(), solana.NewWallet().PublicKey(),
+		solana.NewWallet().PublicKey(), solana.NewWallet().PublicKey(), solana.NewWallet().PublicKey()
+	height := func(h uint32) *uint32 { return &h }
+
+	instructions := []Instruction{
+		{ProgramID: a, OuterIndex: 0, InnerIndex: -1},
+		{ProgramID: b, OuterIndex: 0, InnerIndex: 0, StackHeight: height(2)},
+		{ProgramID: c, OuterIndex: 0, InnerIndex: 1, StackHeight: height(3)},
+		{ProgramID: d, OuterIndex: 0, InnerIndex: 2, StackHeight: height(2)},
+		{ProgramID: e, OuterIndex: 1, InnerIndex: -1},
+		{ProgramID: c, OuterIndex: 1, InnerIndex: 0},
+	}
+	logs := []string{
+		"Program " + a.String() + " invoke [1]",
+		"Program log: in a",
+		"Program " + b.String() + " invoke [2]",
+		"Program " + c.String() + " invoke [3]",
+		"Program log: in c",
+		"Program " + c.String() + " success",
+		"Program " + b.String() + " success",
+		"Program " + d.String() + " invoke [2]",
+		"Program " + d.String() + " success",
+		"Program " + a.String() + " consumed 100 of 200000 compute units",
+		"Program " + a.String() + " success",
+		"Program " + e.String() + " invoke [1]",
+		"Program " + c.String() + " invoke [2]",
+		"Program " + c.String() + " success",
+		"Program " + e.String() + " success",
+	}
+
+	roots := BuildInstructionTree(instructions, logs)
+	if len(roots) != 2 {
+		t.Fatalf("Expected 2 roots, got %d", len(roots))
+	}
+
+	root := roots[0]
+	if len(root.Children) != 2 || !root.Children[0].ProgramID.Equals(b) || !root.Children[1].ProgramID.Equals(d) {
+		t.Fatalf("Unexpected children of first root: %+v", root.Children)
+	}
+	nested := root.Children[0].Children
+	if len(nested) != 1 || !nested[0].ProgramID.Equals(c) || nested[0].Depth != 3 || nested[0].Parent != root.Children[0] {
+		t.Fatalf("Expected c nested under b at depth 3, got %+v", nested)
+	}
+	if len(roots[1].Children) != 1 || roots[1].Children[0].Depth != 2 {
+		t.Errorf("Expected inner instruction without stack height at depth 2")
+	}
+
+	wantRootLogs := []string{logs[0], logs[1], logs[9], logs[10]}
+	if !reflect.DeepEqual(root.Logs, wantRootLogs) {
+		t.Errorf("Unexpected root logs:\n got %q\nwant %q", root.Logs, wantRootLogs)
+	}
+	if !reflect.DeepEqual(nested[0].Logs, logs[3:6]) {
+		t.Errorf("Unexpected nested logs: %q", nested[0].Logs)
+	}
+	if inv := nested[0].Invocation; inv == nil || !inv.ProgramID.Equals(c) || inv.Depth != 3 {
+		t.Errorf("Expected nested node linked to its parsed invocation, got %+v", inv)
+	}
+
+	cpis := FindCPIs(roots, c)
+	if len(cpis) != 2 || cpis[0] != nested[0] || cpis[1] != roots[1].Children[0] {
+		t.Errorf("Expected both CPIs into c, got %d", len(cpis))
+	}
+}
+
+func TestBuildInstructionTreeSkipsPrecompile(t *testing.T) {
+	ed25519 := solana.MustPublicKeyFromBase58("Ed25519SigVerify111111111111111111111111111")
+	a, b := solana.NewWallet().PublicKey(), solana.NewWallet().PublicKey()
+	height := func(h uint32) *uint32 { return &h }
+
+	instructions := []Instruction{
+		{ProgramID: ed25519, OuterIndex: 0, InnerIndex: -1},
+		{ProgramID: a, OuterIndex: 1, InnerIndex: -1},
+		{ProgramID: b, OuterIndex: 1, InnerIndex: 0, StackHeight: height(2)},
+	}
+	logs := []string{
+		"Program " + a.String() + " invoke [1]",
+		"Program " + b.String() + " invoke [2]",
+		"Program " + b.String() + " success",
+		"Program " + a.String() + " success",
+	}
+
+	roots := BuildInstructionTree(instructions, logs)
+	if len(roots) != 2 {
+		t.Fatalf("Expected 2 roots, got %d", len(roots))
+	}
+	if roots[0].Invocation != nil || roots[0].Logs != nil {
+		t.Errorf("Expected no logs for the precompile, got %q", roots[0].Logs)
+	}
+	if !reflect.DeepEqual(roots[1].Logs, []string{logs[0], logs[3]}) {
+		t.Errorf("Unexpected logs after the precompile: %q", roots[1].Logs)
+	}
+	if child := roots[1].Children[0]; child.Invocation == nil || !reflect.DeepEqual(child.Logs, logs[1:3]) {
+		t.Errorf("Unexpected CPI logs: %q", child.Logs)
+	}
+}
@@ -0,0 +1,411 @@
+package convert
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// TransactionErrorKind mirrors the variants of Solana's TransactionError, in
+// bincode tag order.
+type TransactionErrorKind uint32
+
+const (
+	TxErrAccountInUse TransactionErrorKind = iota
+	TxErrAccountLoadedTwice
+	TxErrAccountNotFound
+	TxErrProgramAccountNotFound
+	TxErrInsufficientFundsForFee
+	TxErrInvalidAccountForFee
+	TxErrAlreadyProcessed
+	TxErrBlockhashNotFound
+	TxErrInstructionError
+	TxErrCallChainTooDeep
+	TxErrMissingSignatureForFee
+	TxErrInvalidAccountIndex
+	TxErrSignatureFailure
+	TxErrInvalidProgramForExecution
+	TxErrSanitizeFailure
+	TxErrClusterMaintenance
+	TxErrAccountBorrowOutstanding
+	TxErrWouldExceedMaxBlockCostLimit
+	TxErrUnsupportedVersion
+	TxErrInvalidWritableAccount
+	TxErrWouldExceedMaxAccountCostLimit
+	TxErrWouldExceedAccountDataBlockLimit
+	TxErrTooManyAccountLocks
+	TxErrAddressLookupTableNotFound
+	TxErrInvalidAddressLookupTableOwner
+	TxErrInvalidAddressLookupTableData
+	TxErrInvalidAddressLookupTableIndex
+	TxErrInvalidRentPayingAccount
+	TxErrWouldExceedMaxVoteCostLimit
+	TxErrWouldExceedAccountDataTotalLimit
+	TxErrDuplicateInstruction
+	TxErrInsufficientFundsForRent
+	TxErrMaxLoadedAccountsDataSizeExceeded
+	TxErrInvalidLoadedAccountsDataSizeLimit
+	TxErrResanitizationNeeded
+	TxErrProgramExecutionTemporarilyRestricted
+	TxErrUnbalancedTransaction
+	TxErrProgramCacheHitMaxLimit
+	TxErrCommitCancelled
+)
+
+var transactionErrorNames = []string{
+	"AccountInUse",
+	"AccountLoadedTwice",
+	"AccountNotFound",
+	"ProgramAccountNotFound",
+	"InsufficientFundsForFee",
+	"InvalidAccountForFee",
+	"AlreadyProcessed",
+	"BlockhashNotFound",
+	"InstructionError",
+	"CallChainTooDeep",
+	"MissingSignatureForFee",
+	"InvalidAccountIndex",
+	"SignatureFailure",
+	"InvalidProgramForExecution",
+	"SanitizeFailure",
+	"ClusterMaintenance",
+	"AccountBorrowOutstanding",
+	"WouldExceedMaxBlockCostLimit",
+	"UnsupportedVersion",
+	"InvalidWritableAccount",
+	"WouldExceedMaxAccountCostLimit",
+	"WouldExceedAccountDataBlockLimit",
+	"TooManyAccountLocks",
+	"AddressLookupTableNotFound",
+	"InvalidAddressLookupTableOwner",
+	"InvalidAddressLookupTableData",
+	"InvalidAddressLookupTableIndex",
+	"InvalidRentPayingAccount",
+	"WouldExceedMaxVoteCostLimit",
+	"WouldExceedAccountDataTotalLimit",
+	"DuplicateInstruction",
+	"InsufficientFundsForRent",
+	"MaxLoadedAccountsDataSizeExceeded",
+	"InvalidLoadedAccountsDataSizeLimit",
+	"ResanitizationNeeded",
+	"ProgramExecutionTemporarilyRestricted",
+	"UnbalancedTransaction",
+	"ProgramCacheHitMaxLimit",
+	"CommitCancelled",
+}
+
+func (k TransactionErrorKind) String() string {
+	if int(k) < len(transactionErrorNames) {
+		return transactionErrorNames[k]
+	}
+	return fmt.Sprintf("TransactionError(%d)", uint32(k))
+}
+
+// InstructionErrorKind mirrors the variants of Solana's InstructionError, in
+// bincode tag order.
+type InstructionErrorKind uint32
+
+const (
+	IxErrGenericError InstructionErrorKind = iota
+	IxErrInvalidArgument
+	IxErrInvalidInstructionData
+	IxErrInvalidAccountData
+	IxErrAccountDataTooSmall
+	IxErrInsufficientFunds
+	IxErrIncorrectProgramId
+	IxErrMissingRequiredSignature
+	IxErrAccountAlreadyInitialized
+	IxErrUninitializedAccount
+	IxErrUnbalancedInstruction
+	IxErrModifiedProgramId
+	IxErrExternalAccountLamportSpend
+	IxErrExternalAccountDataModified
+	IxErrReadonlyLamportChange
+	IxErrReadonlyDataModified
+	IxErrDuplicateAccountIndex
+	IxErrExecutableModified
+	IxErrRentEpochModified
+	IxErrNotEnoughAccountKeys
+	IxErrAccountDataSizeChanged
+	IxErrAccountNotExecutable
+	IxErrAccountBorrowFailed
+	IxErrAccountBorrowOutstanding
+	IxErrDuplicateAccountOutOfSync
+	IxErrCustom
+	IxErrInvalidError
+	IxErrExecutableDataModified
+	IxErrExecutableLamportChange
+	IxErrExecutableAccountNotRentExempt
+	IxErrUnsupportedProgramId
+	IxErrCallDepth
+	IxErrMissingAccount
+	IxErrReentrancyNotAllowed
+	IxErrMaxSeedLengthExceeded
+	IxErrInvalidSeeds
+	IxErrInvalidRealloc
+	IxErrComputationalBudgetExceeded
+	IxErrPrivilegeEscalation
+	IxErrProgramEnvironmentSetupFailure
+	IxErrProgramFailedToComplete
+	IxErrProgramFailedToCompile
+	IxErrImmutable
+	IxErrIncorrectAuthority
+	IxErrBorshIoError
+	IxErrAccountNotRentExempt
+	IxErrInvalidAccountOwner
+	IxErrArithmeticOverflow
+	IxErrUnsupportedSysvar
+	IxErrIllegalOwner
+	IxErrMaxAccountsDataAllocationsExceeded
+	IxErrMaxAccountsExceeded
+	IxErrMaxInstructionTraceLengthExceeded
+	IxErrBuiltinProgramsMustConsumeComputeUnits
+)
+
+var instructionErrorNames = []string{
+	"GenericError",
+	"InvalidArgument",
+	"InvalidInstructionData",
+	"InvalidAccountData",
+	"AccountDataTooSmall",
+	"InsufficientFunds",
+	"IncorrectProgramId",
+	"MissingRequiredSignature",
+	"AccountAlreadyInitialized",
+	"UninitializedAccount",
+	"UnbalancedInstruction",
+	"ModifiedProgramId",
+	"ExternalAccountLamportSpend",
+	"ExternalAccountDataModified",
+	"ReadonlyLamportChange",
+	"ReadonlyDataModified",
+	"DuplicateAccountIndex",
+	"ExecutableModified",
+	"RentEpochModified",
+	"NotEnoughAccountKeys",
+	"AccountDataSizeChanged",
+	"AccountNotExecutable",
+	"AccountBorrowFailed",
+	"AccountBorrowOutstanding",
+	"DuplicateAccountOutOfSync",
+	"Custom",
+	"InvalidError",
+	"ExecutableDataModified",
+	"ExecutableLamportChange",
+	"ExecutableAccountNotRentExempt",
+	"UnsupportedProgramId",
+	"CallDepth",
+	"MissingAccount",
+	"ReentrancyNotAllowed",
+	"MaxSeedLengthExceeded",
+	"InvalidSeeds",
+	"InvalidRealloc",
+	"ComputationalBudgetExceeded",
+	"PrivilegeEscalation",
+	"ProgramEnvironmentSetupFailure",
+	"ProgramFailedToComplete",
+	"ProgramFailedToCompile",
+	"Immutable",
+	"IncorrectAuthority",
+	"BorshIoError",
+	"AccountNotRentExempt",
+	"InvalidAccountOwner",
+	"ArithmeticOverflow",
+	"UnsupportedSysvar",
+	"IllegalOwner",
+	"MaxAccountsDataAllocationsExceeded",
+	"MaxAccountsExceeded",
+	"MaxInstructionTraceLengthExceeded",
+	"BuiltinProgramsMustConsumeComputeUnits",
+}
+
+func (k InstructionErrorKind) String() string {
+	if int(k) < len(instructionErrorNames) {
+		return instructionErrorNames[k]
+	}
+	return fmt.Sprintf("InstructionError(%d)", uint32(k))
+}
+
+type TransactionError struct {
+	Kind TransactionErrorKind
+	// InstructionIndex is set for InstructionError and DuplicateInstruction.
+	InstructionIndex uint8
+	// AccountIndex is set for InsufficientFundsForRent and
+	// ProgramExecutionTemporarilyRestricted.
+	AccountIndex     uint8
+	InstructionError *InstructionError
+}
+
+func (e *TransactionError) Error() string {
+	switch e.Kind {
+	case TxErrInstructionError:
+		return fmt.Sprintf("Error processing Instruction %d: %s", e.InstructionIndex, e.InstructionError)
+	case TxErrDuplicateInstruction:
+		return fmt.Sprintf("DuplicateInstruction(%d)", e.InstructionIndex)
+	case TxErrInsufficientFundsForRent, TxErrProgramExecutionTemporarilyRestricted:
+		return fmt.Sprintf("%s { account_index: %d }", e.Kind, e.AccountIndex)
+	default:
+		return e.Kind.String()
+	}
+}
+
+func (e *TransactionError) String() string {
+	return e.Error()
+}
+
+func (e *TransactionError) Unwrap() error {
+	if e.InstructionError == nil {
+		return nil
+	}
+	return e.InstructionError
+}
+
+// CustomCode returns the program error code of an
+// InstructionError(_, Custom(code)).
+func (e *TransactionError) CustomCode() (uint32, bool) {
+	if e.InstructionError == nil || e.InstructionError.Kind != IxErrCustom {
+		return 0, false
+	}
+	return e.InstructionError.Code, true
+}
+
+type InstructionError struct {
+	Kind InstructionErrorKind
+	// Code is the program error code of Custom.
+	Code uint32
+	// Message is the text of BorshIoError, empty on nodes that no longer
+	// serialise it.
+	Message string
+}
+
+func (e *InstructionError) Error() string {
+	switch e.Kind {
+	case IxErrCustom:
+		return fmt.Sprintf("custom program error: 0x%x", e.Code)
+	case IxErrBorshIoError:
+		if e.Message != "" {
+			return fmt.Sprintf("BorshIoError(%s)", e.Message)
+		}
+	}
+	return e.Kind.String()
+}
+
+func (e *InstructionError) String() string {
+	return e.Error()
+}
+
+// DecodeTransactionError decodes the bincode bytes of pb.TransactionError.Err
+// and SubscribeUpdateTransactionStatus.Err. It returns nil for empty input.
+func DecodeTransactionError(data []byte) (*TransactionError, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	d := &bincodeReader{data: data}
+	tag, err := d.uint32()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &TransactionError{Kind: TransactionErrorKind(tag)}
+	switch result.Kind {
+	case TxErrInstructionError:
+		if result.InstructionIndex, err = d.uint8(); err != nil {
+			return nil, err
+		}
+		if result.InstructionError, err = decodeInstructionError(d); err != nil {
+			return nil, err
+		}
+	case TxErrDuplicateInstruction:
+		if result.InstructionIndex, err = d.uint8(); err != nil {
+			return nil, err
+		}
+	case TxErrInsufficientFundsForRent, TxErrProgramExecutionTemporarilyRestricted:
+		if result.AccountIndex, err = d.uint8(); err != nil {
+			return nil, err
+		}
+	default:
+		if int(tag) >= len(transactionErrorNames) {
+			return nil, fmt.Errorf("unknown transaction error tag %d", tag)
+		}
+	}
+	return result, nil
+}
+
+func decodeInstructionError(d *bincodeReader) (*InstructionError, error) {
+	tag, err := d.uint32()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &InstructionError{Kind: InstructionErrorKind(tag)}
+	switch result.Kind {
+	case IxErrCustom:
+		if result.Code, err = d.uint32(); err != nil {
+			return nil, err
+		}
+	case IxErrBorshIoError:
+		if d.remaining() > 0 {
+			if result.Message, err = d.string(); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		if int(tag) >= len(instructionErrorNames) {
+			return nil, fmt.Errorf("unknown instruction error tag %d", tag)
+		}
+	}
+	return result, nil
+}
+
+type bincodeReader struct {
+	data []byte
+	pos  int
+}
+
+func (d *bincodeReader) remaining() int {
+	return len(d.data) - d.pos
+}
+
+func (d *bincodeReader) next(n int) ([]byte, error) {
+	if d.remaining() < n {
+		return nil, fmt.Errorf("transaction error truncated at byte %d", d.pos)
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+func (d *bincodeReader) uint8() (uint8, error) {
+	b, err := d.next(1)
+	if err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+func (d *bincodeReader) uint32() (uint32, error) {
+	b, err := d.next(4)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint32(b), nil
+}
+
+func (d *bincodeReader) string() (string, error) {
+	b, err := d.next(8)
+	if err != nil {
+		return "", err
+	}
+	n := binary.LittleEndian.Uint64(b)
+	if n > uint64(d.remaining()) {
+		return "", fmt.Errorf("transaction error truncated at byte %d", d.pos)
+	}
+	s, err := d.next(int(n))
+	return string(s), err
+}
+
+// TransactionError decodes Err, returning nil when the transaction
+// succeeded.
+func (m *TransactionMeta) TransactionError() (*TransactionError, error) {
+	return DecodeTransactionError(m.Err)
+}
@@ -0,0 +1,63 @@
+package convert
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDecodeTransactionError(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{name: "unit", data: []byte{4, 0, 0, 0}, want: "InsufficientFundsForFee"},
+		{name: "custom", data: []byte{8, 0, 0, 0, 2, 25, 0, 0, 0, 0x71, 0x17, 0, 0}, want: "Error processing Instruction 2: custom program error: 0x1771"},
+		{name: "instruction unit", data: []byte{8, 0, 0, 0, 0, 5, 0, 0, 0}, want: "Error processing Instruction 0: InsufficientFunds"},
+		{name: "borsh message", data: []byte{8, 0, 0, 0, 1, 44, 0, 0, 0, 2, 0, 0, 0, 0, 0, 0, 0, 'h', 'i'}, want: "Error processing Instruction 1: BorshIoError(hi)"},
+		{name: "duplicate instruction", data: []byte{30, 0, 0, 0, 3}, want: "DuplicateInstruction(3)"},
+		{name: "rent", data: []byte{31, 0, 0, 0, 7}, want: "InsufficientFundsForRent { account_index: 7 }"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txErr, err := DecodeTransactionError(tt.data)
+			if err != nil {
+				t.Fatalf("DecodeTransactionError failed: %v", err)
+			}
+			if txErr.String() != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, txErr.String())
+			}
+		})
+	}
+}
+
+func TestTransactionErrorAs(t *testing.T) {
+	txErr, err := DecodeTransactionError([]byte{8, 0, 0, 0, 2, 25, 0, 0, 0, 0x71, 0x17, 0, 0})
+	if err != nil {
+		t.Fatalf("DecodeTransactionError failed: %v", err)
+	}
+
+	var wrapped error = txErr
+	var ixErr *InstructionError
+	if !errors.As(wrapped, &ixErr) || ixErr.Kind != IxErrCustom || ixErr.Code != 0x1771 {
+		t.Errorf("Expected custom instruction error via errors.As, got %v", ixErr)
+	}
+	if code, ok := txErr.CustomCode(); !ok || code != 0x1771 {
+		t.Errorf("Expected custom code 0x1771, got %d %v", code, ok)
+	}
+	if txErr.Kind != TxErrInstructionError || txErr.InstructionIndex != 2 {
+		t.Errorf("Unexpected kind or index: %v %d", txErr.Kind, txErr.InstructionIndex)
+	}
+}
+
+func TestDecodeTransactionErrorInvalid(t *testing.T) {
+	if txErr, err := DecodeTransactionError(nil); txErr != nil || err != nil {
+		t.Errorf("Expected nil for empty input, got %v %v", txErr, err)
+	}
+	for _, data := range [][]byte{{8, 0}, {8, 0, 0, 0, 1, 25, 0, 0, 0}, {200, 0, 0, 0}} {
+		if _, err := DecodeTransactionError(data); err == nil {
+			t.Errorf("Expected error for %x", data)
+		}
+	}
+}
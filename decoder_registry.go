@@ -0,0 +1,151 @@
+package yellowstone
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/andrew-solarstorm/yellowstone-grpc-client-go/anchor"
+	pb "github.com/andrew-solarstorm/yellowstone-grpc-client-go/proto"
+	"github.com/andrew-solarstorm/yellowstone-grpc-client-go/token"
+	"github.com/gagliardetto/solana-go"
+)
+
+var ErrNoDecoder = errors.New("no decoder registered for account")
+
+type AccountDecoder func(info *pb.SubscribeUpdateAccountInfo) (any, error)
+
+// DecodedAccount is an account update with the value decoded from its data.
+// Err is ErrNoDecoder when no decoder matched.
+type DecodedAccount struct {
+	*pb.SubscribeUpdateAccount
+	Value any
+	Err   error
+}
+
+// DecoderRegistry picks an account decoder by owner program and, optionally,
+// by the discriminator prefix of the account data. A decoder registered with
+// a discriminator takes precedence over one registered without.
+type DecoderRegistry struct {
+	mu     sync.RWMutex
+	owners map[solana.PublicKey]*ownerDecoders
+}
+
+type ownerDecoders struct {
+	fallback AccountDecoder
+	byPrefix map[string]AccountDecoder
+	// lengths holds the distinct discriminator lengths, longest first.
+	lengths []int
+}
+
+func NewDecoderRegistry() *DecoderRegistry {
+	return &DecoderRegistry{owners: make(map[solana.PublicKey]*ownerDecoders)}
+}
+
+func (r *DecoderRegistry) Register(owner solana.PublicKey, discriminator []byte, decoder AccountDecoder) *DecoderRegistry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	decoders, ok := r.owners[owner]
+	if !ok {
+		decoders = &ownerDecoders{byPrefix: make(map[string]AccountDecoder)}
+		r.owners[owner] = decoders
+	}
+
+	if len(discriminator) == 0 {
+		decoders.fallback = decoder
+		return r
+	}
+
+	decoders.byPrefix[string(discriminator)] = decoder
+	if !slices.Contains(decoders.lengths, len(discriminator)) {
+		decoders.lengths = append(decoders.lengths, len(discriminator))
+		sort.Sort(sort.Reverse(sort.IntSlice(decoders.lengths)))
+	}
+	return r
+}
+
+// RegisterTokenPrograms decodes SPL Token and Token-2022 accounts into
+// *token.Mint, *token.Account and *token.Multisig values.
+func (r *DecoderRegistry) RegisterTokenPrograms() *DecoderRegistry {
+	decode := func(info *pb.SubscribeUpdateAccountInfo) (any, error) {
+		return token.DecodeAccountInfo(info)
+	}
+	r.Register(solana.TokenProgramID, nil, decode)
+	return r.Register(solana.Token2022ProgramID, nil, decode)
+}
+
+// RegisterAnchorIDL registers every account type of the IDL under its program
+// address, decoding into *anchor.Account values.
+func (r *DecoderRegistry) RegisterAnchorIDL(idl *anchor.IDL) error {
+	if idl.Address.IsZero() {
+		return fmt.Errorf("idl %s has no program address", idl.Name)
+	}
+
+	for i := range idl.Accounts {
+		def := &idl.Accounts[i]
+		r.Register(idl.Address, def.Discriminator, func(info *pb.SubscribeUpdateAccountInfo) (any, error) {
+			return def.Decode(info.GetData())
+		})
+	}
+	return nil
+}
+
+func (r *DecoderRegistry) LoadAnchorIDL(path string) error {
+	idl, err := anchor.LoadIDL(path)
+	if err != nil {
+		return err
+	}
+	return r.RegisterAnchorIDL(idl)
+}
+
+func (r *DecoderRegistry) Decode(info *pb.SubscribeUpdateAccountInfo) (any, error) {
+	decoder := r.lookup(info)
+	if decoder == nil {
+		return nil, ErrNoDecoder
+	}
+	return decoder(info)
+}
+
+func (r *DecoderRegistry) lookup(info *pb.SubscribeUpdateAccountInfo) AccountDecoder {
+	if len(info.GetOwner()) != solana.PublicKeyLength {
+		return nil
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	decoders, ok := r.owners[solana.PublicKeyFromBytes(info.GetOwner())]
+	if !ok {
+		return nil
+	}
+
+	data := info.GetData()
+	for _, n := range decoders.lengths {
+		if len(data) < n {
+			continue
+		}
+		if decoder, ok := decoders.byPrefix[string(data[:n])]; ok {
+			return decoder
+		}
+	}
+	return decoders.fallback
+}
+
+func (r *DecoderRegistry) DecodeUpdate(update *pb.SubscribeUpdateAccount) *DecodedAccount {
+	value, err := r.Decode(update.GetAccount())
+	return &DecodedAccount{
+		SubscribeUpdateAccount: update,
+		Value:                  value,
+		Err:                    err,
+	}
+}
+
+// OnAccount adapts fn for use as Handlers.OnAccount.
+func (r *DecoderRegistry) OnAccount(fn func(*DecodedAccount, []string)) func(*pb.SubscribeUpdateAccount, []string) {
+	return func(update *pb.SubscribeUpdateAccount, filters []string) {
+		fn(r.DecodeUpdate(update), filters)
+	}
+}
@@ -0,0 +1,83 @@
+package yellowstone
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/andrew-solarstorm/yellowstone-grpc-client-go/anchor"
+	pb "github.com/andrew-solarstorm/yellowstone-grpc-client-go/proto"
+	"github.com/andrew-solarstorm/yellowstone-grpc-client-go/token"
+	"github.com/gagliardetto/solana-go"
+)
+
+func TestDecoderRegistryDispatch(t *testing.T) {
+	program := solana.NewWallet().PublicKey()
+	registry := NewDecoderRegistry().
+		Register(program, []byte{1, 2}, func(*pb.SubscribeUpdateAccountInfo) (any, error) { return "short", nil }).
+		Register(program, []byte{1, 2, 3, 4}, func(*pb.SubscribeUpdateAccountInfo) (any, error) { return "long", nil }).
+		Register(program, nil, func(*pb.SubscribeUpdateAccountInfo) (any, error) { return "fallback", nil })
+
+	tests := []struct {
+		data []byte
+		want string
+	}{
+		{data: []byte{1, 2, 3, 4, 5}, want: "long"},
+		{data: []byte{1, 2, 9}, want: "short"},
+		{data: []byte{9}, want: "fallback"},
+	}
+	for _, tt := range tests {
+		value, err := registry.Decode(&pb.SubscribeUpdateAccountInfo{Owner: program.Bytes(), Data: tt.data})
+		if err != nil || value != tt.want {
+			t.Errorf("Data %v: expected %q, got %v (%v)", tt.data, tt.want, value, err)
+		}
+	}
+
+	_, err := registry.Decode(&pb.SubscribeUpdateAccountInfo{Owner: solana.SystemProgramID.Bytes()})
+	if !errors.Is(err, ErrNoDecoder) {
+		t.Errorf("Expected ErrNoDecoder, got %v", err)
+	}
+}
+
+func TestDecoderRegistryTokenAndAnchor(t *testing.T) {
+	idl := `{
+  "address": "Fg6PaFpoGXkYsidMpWTK6W2BeZ7FEfcYkg476zPFsLnS",
+  "metadata": {"name": "counter"},
+  "accounts": [{"name": "Counter", "discriminator": [1, 1, 1, 1, 1, 1, 1, 1]}],
+  "types": [{"name": "Counter", "type": {"kind": "struct", "fields": [{"name": "count", "type": "u64"}]}}]
+}`
+	path := filepath.Join(t.TempDir(), "counter.json")
+	if err := os.WriteFile(path, []byte(idl), 0o600); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	registry := NewDecoderRegistry().RegisterTokenPrograms()
+	if err := registry.LoadAnchorIDL(path); err != nil {
+		t.Fatalf("LoadAnchorIDL failed: %v", err)
+	}
+
+	data := binary.LittleEndian.AppendUint64([]byte{1, 1, 1, 1, 1, 1, 1, 1}, 3)
+	var decoded *DecodedAccount
+	onAccount := registry.OnAccount(func(account *DecodedAccount, _ []string) { decoded = account })
+	onAccount(&pb.SubscribeUpdateAccount{
+		Slot: 9,
+		Account: &pb.SubscribeUpdateAccountInfo{
+			Owner: solana.MustPublicKeyFromBase58("Fg6PaFpoGXkYsidMpWTK6W2BeZ7FEfcYkg476zPFsLnS").Bytes(),
+			Data:  data,
+		},
+	}, nil)
+
+	counter, ok := decoded.Value.(*anchor.Account)
+	if decoded.Err != nil || !ok || counter.Name != "Counter" || counter.Fields["count"] != uint64(3) || decoded.Slot != 9 {
+		t.Errorf("Unexpected anchor decode: %+v %v", decoded.Value, decoded.Err)
+	}
+
+	mint := make([]byte, token.MintSize)
+	mint[44] = 6
+	value, err := registry.Decode(&pb.SubscribeUpdateAccountInfo{Owner: solana.Token2022ProgramID.Bytes(), Data: mint})
+	if m, ok := value.(*token.Mint); err != nil || !ok || m.Decimals != 6 {
+		t.Errorf("Unexpected token decode: %+v %v", value, err)
+	}
+}
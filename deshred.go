@@ -0,0 +1,116 @@
+package yellowstone
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	pb "github.com/andrew-solarstorm/yellowstone-grpc-client-go/proto"
+	"github.com/gagliardetto/solana-go"
+)
+
+type deshredStream struct {
+	pb.Geyser_SubscribeDeshredClient
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	sendMu sync.Mutex
+}
+
+func (s *deshredStream) Send(request *pb.SubscribeDeshredRequest) error {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	return s.Geyser_SubscribeDeshredClient.Send(request)
+}
+
+func (s *deshredStream) Recv() (*pb.SubscribeUpdateDeshred, error) {
+	msg, err := s.Geyser_SubscribeDeshredClient.Recv()
+	if err != nil {
+		err = streamCause(s.ctx, err)
+		s.cancel(nil)
+	}
+	return msg, err
+}
+
+func (s *deshredStream) abort(err error) {
+	s.cancel(err)
+}
+
+func (c *GeyserGrpcClient) SubscribeDeshred(ctx context.Context) (pb.Geyser_SubscribeDeshredClient, error) {
+	return c.SubscribeDeshredWithRequest(ctx, nil)
+}
+
+func (c *GeyserGrpcClient) SubscribeDeshredWithRequest(
+	ctx context.Context,
+	request *pb.SubscribeDeshredRequest,
+) (pb.Geyser_SubscribeDeshredClient, error) {
+	if err := ValidateSubscribeDeshredRequest(request); err != nil {
+		return nil, NewInvalidSubscribeRequestError(err)
+	}
+
+	streamCtx, cancel := context.WithCancelCause(ctx)
+	stream, err := c.Geyser.SubscribeDeshred(streamCtx)
+	if err != nil {
+		cancel(nil)
+		return nil, NewGrpcStatusError(err)
+	}
+
+	s := &deshredStream{
+		Geyser_SubscribeDeshredClient: stream,
+		ctx:                           streamCtx,
+		cancel:                        cancel,
+	}
+
+	if request != nil {
+		if err := s.Send(request); err != nil {
+			cancel(nil)
+			return nil, NewSubscribeSendError(err)
+		}
+	}
+
+	return s, nil
+}
+
+func (c *GeyserGrpcClient) StartDeshred(
+	stream pb.Geyser_SubscribeDeshredClient,
+	fn func(*pb.SubscribeUpdateDeshred) error,
+) error {
+	defer c.cancel()
+	_, streamErr, fnErr := c.receiveDeshred(stream, fn)
+	if fnErr != nil {
+		return fnErr
+	}
+	if c.ctx.Err() != nil {
+		return nil
+	}
+	return streamErr
+}
+
+func (c *GeyserGrpcClient) receiveDeshred(
+	stream pb.Geyser_SubscribeDeshredClient,
+	fn func(*pb.SubscribeUpdateDeshred) error,
+) (received bool, streamErr error, fnErr error) {
+	return receiveStream[*pb.SubscribeDeshredRequest, *pb.SubscribeUpdateDeshred](c, stream, func(id int32) *pb.SubscribeDeshredRequest {
+		return &pb.SubscribeDeshredRequest{Ping: &pb.SubscribeRequestPing{Id: id}}
+	}, fn)
+}
+
+// DeshredAccountKeys returns the full account key list of a deshred
+// transaction: static message keys followed by the writable and then the
+// readonly addresses loaded from address lookup tables, matching the indexes
+// used by compiled instructions.
+func DeshredAccountKeys(info *pb.SubscribeUpdateDeshredTransactionInfo) (solana.PublicKeySlice, error) {
+	static := info.GetTransaction().GetMessage().GetAccountKeys()
+	loadedWritable := info.GetLoadedWritableAddresses()
+	loadedReadonly := info.GetLoadedReadonlyAddresses()
+
+	keys := make(solana.PublicKeySlice, 0, len(static)+len(loadedWritable)+len(loadedReadonly))
+	for _, group := range [][][]byte{static, loadedWritable, loadedReadonly} {
+		for _, raw := range group {
+			if len(raw) != solana.PublicKeyLength {
+				return nil, fmt.Errorf("invalid account key length %d at index %d", len(raw), len(keys))
+			}
+			keys = append(keys, solana.PublicKeyFromBytes(raw))
+		}
+	}
+	return keys, nil
+}
@@ -0,0 +1,120 @@
+package yellowstone
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	pb "github.com/andrew-solarstorm/yellowstone-grpc-client-go/proto"
+	"github.com/gagliardetto/solana-go"
+	"google.golang.org/grpc"
+)
+
+func deshredRequest() *pb.SubscribeDeshredRequest {
+	return &pb.SubscribeDeshredRequest{
+		DeshredTransactions: map[string]*pb.SubscribeRequestFilterDeshredTransactions{"all": {}},
+	}
+}
+
+func TestStartDeshredAnswersPingAndDelivers(t *testing.T) {
+	replies := make(chan *pb.SubscribeDeshredRequest, 1)
+	srv := &testGeyserServer{
+		subscribeDeshred: func(stream grpc.BidiStreamingServer[pb.SubscribeDeshredRequest, pb.SubscribeUpdateDeshred]) error {
+			if _, err := stream.Recv(); err != nil {
+				return err
+			}
+			ping := &pb.SubscribeUpdateDeshred{UpdateOneof: &pb.SubscribeUpdateDeshred_Ping{Ping: &pb.SubscribeUpdatePing{}}}
+			if err := stream.Send(ping); err != nil {
+				return err
+			}
+			req, err := stream.Recv()
+			if err != nil {
+				return err
+			}
+			replies <- req
+			return stream.Send(&pb.SubscribeUpdateDeshred{
+				UpdateOneof: &pb.SubscribeUpdateDeshred_DeshredTransaction{
+					DeshredTransaction: &pb.SubscribeUpdateDeshredTransaction{Slot: 42},
+				},
+			})
+		},
+	}
+
+	client := connectTestClient(t, startTestServer(t, srv))
+	stream, err := client.SubscribeDeshredWithRequest(context.Background(), deshredRequest())
+	if err != nil {
+		t.Fatalf("SubscribeDeshredWithRequest failed: %v", err)
+	}
+
+	var slot uint64
+	client.StartDeshred(stream, func(update *pb.SubscribeUpdateDeshred) error {
+		if tx := update.GetDeshredTransaction(); tx != nil {
+			slot = tx.Slot
+		}
+		return nil
+	})
+
+	select {
+	case req := <-replies:
+		if req.GetPing().GetId() != serverPingReplyID {
+			t.Fatalf("Expected ping reply, got %v", req)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for ping reply")
+	}
+
+	if slot != 42 {
+		t.Errorf("Expected deshred transaction at slot 42, got %d", slot)
+	}
+}
+
+func TestSubscribeDeshredRejectsInvalidRequest(t *testing.T) {
+	client := connectTestClient(t, startTestServer(t, &testGeyserServer{}))
+
+	request := &pb.SubscribeDeshredRequest{
+		DeshredTransactions: map[string]*pb.SubscribeRequestFilterDeshredTransactions{
+			"bad": {AccountInclude: []string{"not-a-pubkey"}},
+		},
+	}
+	_, err := client.SubscribeDeshredWithRequest(context.Background(), request)
+
+	var filterErr *FilterError
+	if !errors.As(err, &filterErr) || filterErr.Kind != "deshred_transactions" || filterErr.Name != "bad" {
+		t.Fatalf("Expected deshred filter error, got %v", err)
+	}
+}
+
+func TestDeshredAccountKeys(t *testing.T) {
+	static := []solana.PublicKey{solana.NewWallet().PublicKey(), solana.SystemProgramID}
+	writable := solana.NewWallet().PublicKey()
+	readonly := solana.TokenProgramID
+
+	info := &pb.SubscribeUpdateDeshredTransactionInfo{
+		Transaction: &pb.Transaction{
+			Message: &pb.Message{AccountKeys: [][]byte{static[0].Bytes(), static[1].Bytes()}},
+		},
+		LoadedWritableAddresses: [][]byte{writable.Bytes()},
+		LoadedReadonlyAddresses: [][]byte{readonly.Bytes()},
+	}
+
+	keys, err := DeshredAccountKeys(info)
+	if err != nil {
+		t.Fatalf("DeshredAccountKeys failed: %v", err)
+	}
+
+	expected := solana.PublicKeySlice{static[0], static[1], writable, readonly}
+	if len(keys) != len(expected) {
+		t.Fatalf("Expected %d keys, got %d", len(expected), len(keys))
+	}
+	for i := range expected {
+		if !keys[i].Equals(expected[i]) {
+			t.Errorf("Key %d: expected %s, got %s", i, expected[i], keys[i])
+		}
+	}
+
+	info.LoadedReadonlyAddresses = [][]byte{{1, 2, 3}}
+	if _, err := DeshredAccountKeys(info); err == nil {
+		t.Error("Expected error for malformed loaded address")
+	}
+}
@@ -0,0 +1,92 @@
+package yellowstone
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+const defaultHTTP2WindowSize = 65535
+
+func (b *GeyserGrpcBuilder) contextDialer(recorder *connectRecorder) func(context.Context, string) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: b.connectTimeout}
+
+	// nil keeps the Go default; a non-positive duration disables keepalive.
+	if b.tcpKeepalive != nil {
+		if *b.tcpKeepalive > 0 {
+			dialer.KeepAliveConfig = net.KeepAliveConfig{
+				Enable:   true,
+				Idle:     *b.tcpKeepalive,
+				Interval: *b.tcpKeepalive,
+			}
+		} else {
+			dialer.KeepAlive = -1
+		}
+	}
+
+	return func(ctx context.Context, address string) (net.Conn, error) {
+		conn, err := dialer.DialContext(ctx, "tcp", address)
+		if err != nil {
+			recorder.record(err, false)
+			return nil, err
+		}
+
+		if tcpConn, ok := conn.(*net.TCPConn); ok {
+			if err := tcpConn.SetNoDelay(b.tcpNodelay); err != nil {
+				conn.Close()
+				return nil, err
+			}
+		}
+
+		return conn, nil
+	}
+}
+
+func (b *GeyserGrpcBuilder) windowOptions() []grpc.DialOption {
+	if b.http2AdaptiveWindow != nil && *b.http2AdaptiveWindow {
+		// Any explicit window size would switch gRPC to static flow control.
+		return nil
+	}
+
+	var opts []grpc.DialOption
+	connWindow := int32(b.initialConnWindowSize)
+	streamWindow := int32(b.initialStreamWindowSize)
+
+	if b.http2AdaptiveWindow != nil {
+		if connWindow <= 0 {
+			connWindow = defaultHTTP2WindowSize
+		}
+		if streamWindow <= 0 {
+			streamWindow = defaultHTTP2WindowSize
+		}
+	}
+
+	if connWindow > 0 {
+		opts = append(opts, grpc.WithStaticConnWindowSize(connWindow))
+	}
+	if streamWindow > 0 {
+		opts = append(opts, grpc.WithStaticStreamWindowSize(streamWindow))
+	}
+
+	return opts
+}
+
+func timeoutInterceptor(timeout time.Duration) grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply interface{},
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		if _, ok := ctx.Deadline(); !ok {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
@@ -0,0 +1,65 @@
+package yellowstone
+
+import (
+	"context"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func getSockoptInt(t *testing.T, conn net.Conn, level, opt int) int {
+	t.Helper()
+
+	raw, err := conn.(*net.TCPConn).SyscallConn()
+	if err != nil {
+		t.Fatalf("SyscallConn failed: %v", err)
+	}
+
+	var value int
+	var sockErr error
+	raw.Control(func(fd uintptr) {
+		value, sockErr = syscall.GetsockoptInt(int(fd), level, opt)
+	})
+	if sockErr != nil {
+		t.Fatalf("GetsockoptInt failed: %v", sockErr)
+	}
+	return value
+}
+
+func TestContextDialerTCPOptions(t *testing.T) {
+	addr := startTestServer(t, &testGeyserServer{})
+
+	disabled := time.Duration(0)
+	builder := BuildFromStatic("http://" + addr).TCPNodelay(false).TCPKeepalive(&disabled)
+	conn, err := builder.contextDialer(nil)(context.Background(), addr)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer conn.Close()
+
+	if getSockoptInt(t, conn, syscall.IPPROTO_TCP, syscall.TCP_NODELAY) != 0 {
+		t.Error("Expected TCP_NODELAY to be disabled")
+	}
+	if getSockoptInt(t, conn, syscall.SOL_SOCKET, syscall.SO_KEEPALIVE) != 0 {
+		t.Error("Expected SO_KEEPALIVE to be disabled")
+	}
+
+	interval := 7 * time.Second
+	builder = BuildFromStatic("http://" + addr).TCPNodelay(true).TCPKeepalive(&interval)
+	conn, err = builder.contextDialer(nil)(context.Background(), addr)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer conn.Close()
+
+	if getSockoptInt(t, conn, syscall.IPPROTO_TCP, syscall.TCP_NODELAY) == 0 {
+		t.Error("Expected TCP_NODELAY to be enabled")
+	}
+	if getSockoptInt(t, conn, syscall.SOL_SOCKET, syscall.SO_KEEPALIVE) == 0 {
+		t.Error("Expected SO_KEEPALIVE to be enabled")
+	}
+	if got := getSockoptInt(t, conn, syscall.IPPROTO_TCP, syscall.TCP_KEEPINTVL); got != 7 {
+		t.Errorf("Expected keepalive interval 7s, got %ds", got)
+	}
+}
@@ -0,0 +1,189 @@
+package yellowstone
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	pb "github.com/andrew-solarstorm/yellowstone-grpc-client-go/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
+)
+
+type compressionRecorder struct {
+	mu    sync.Mutex
+	inbox []string
+}
+
+func (r *compressionRecorder) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+func (r *compressionRecorder) HandleRPC(_ context.Context, s stats.RPCStats) {
+	if header, ok := s.(*stats.InHeader); ok {
+		r.mu.Lock()
+		r.inbox = append(r.inbox, header.Compression)
+		r.mu.Unlock()
+	}
+}
+
+func (r *compressionRecorder) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (r *compressionRecorder) HandleConn(context.Context, stats.ConnStats) {}
+
+func (r *compressionRecorder) last() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.inbox) == 0 {
+		return ""
+	}
+	return r.inbox[len(r.inbox)-1]
+}
+
+func TestSendCompressed(t *testing.T) {
+	recorder := &compressionRecorder{}
+	addr := startTestServer(t, &testGeyserServer{}, grpc.StatsHandler(recorder))
+
+	for _, name := range []string{"", CompressionGzip, CompressionZstd} {
+		builder := BuildFromStatic("http://" + addr).SendCompressed(true)
+		if name != "" {
+			builder = builder.CompressionEncoding(name)
+		}
+		if err := getTestVersion(t, builder); err != nil {
+			t.Fatalf("GetVersion with %q compression failed: %v", name, err)
+		}
+
+		want := name
+		if want == "" {
+			want = CompressionGzip
+		}
+		if got := recorder.last(); got != want {
+			t.Errorf("Expected request encoding %q, got %q", want, got)
+		}
+	}
+
+	if err := getTestVersion(t, BuildFromStatic("http://"+addr)); err != nil {
+		t.Fatalf("GetVersion failed: %v", err)
+	}
+	if got := recorder.last(); got != "" {
+		t.Errorf("Expected uncompressed request, got %q", got)
+	}
+
+	if _, err := BuildFromStatic("http://" + addr).SendCompressed(true).CompressionEncoding("brotli").ConnectLazy(); err == nil {
+		t.Error("Expected unknown compression encoding to be rejected")
+	}
+}
+
+func TestAcceptCompressed(t *testing.T) {
+	srv := &testGeyserServer{
+		getVersion: func(ctx context.Context) (*pb.GetVersionResponse, error) {
+			supported, err := grpc.ClientSupportedCompressors(ctx)
+			if err != nil {
+				return nil, err
+			}
+			joined := strings.Join(supported, ",")
+			if !strings.Contains(joined, CompressionGzip) || !strings.Contains(joined, CompressionZstd) {
+				return nil, status.Error(codes.FailedPrecondition, "client does not accept gzip and zstd: "+joined)
+			}
+			if err := grpc.SetSendCompressor(ctx, CompressionZstd); err != nil {
+				return nil, err
+			}
+			return &pb.GetVersionResponse{Version: strings.Repeat("compressed", 100)}, nil
+		},
+	}
+	addr := startTestServer(t, srv)
+
+	if err := getTestVersion(t, BuildFromStatic("http://"+addr)); err != nil {
+		t.Fatalf("Expected compressed response to be decoded, got %v", err)
+	}
+	if err := getTestVersion(t, BuildFromStatic("http://"+addr).AcceptCompressed(true)); err != nil {
+		t.Fatalf("Expected compressed response to be decoded, got %v", err)
+	}
+	if err := getTestVersion(t, BuildFromStatic("http://"+addr).AcceptCompressed(false)); err != nil {
+		t.Fatalf("Expected AcceptCompressed(false) to be a no-op, got %v", err)
+	}
+}
+
+func TestTimeoutAppliesDefaultDeadline(t *testing.T) {
+	srv := &testGeyserServer{
+		getVersion: func(ctx context.Context) (*pb.GetVersionResponse, error) {
+			if _, ok := ctx.Deadline(); !ok {
+				return nil, status.Error(codes.FailedPrecondition, "missing deadline")
+			}
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	}
+	addr := startTestServer(t, srv)
+
+	client, err := BuildFromStatic("http://" + addr).Timeout(50 * time.Millisecond).ConnectLazy()
+	if err != nil {
+		t.Fatalf("ConnectLazy failed: %v", err)
+	}
+	defer client.Close()
+
+	start := time.Now()
+	_, err = client.GetVersion(context.Background())
+	if status.Code(err.(*GeyserGrpcClientError).Err) != codes.DeadlineExceeded {
+		t.Fatalf("Expected DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Default deadline was not applied, call took %v", elapsed)
+	}
+}
+
+func TestConnectTimeout(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	addr := lis.Addr().String()
+	lis.Close()
+
+	start := time.Now()
+	_, err = BuildFromStatic("http://" + addr).ConnectTimeout(200 * time.Millisecond).Connect(context.Background())
+	if err == nil {
+		t.Fatal("Expected Connect to fail for unreachable endpoint")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Connect did not honour connect timeout, took %v", elapsed)
+	}
+
+	addr = startTestServer(t, &testGeyserServer{})
+	client, err := BuildFromStatic("http://" + addr).ConnectTimeout(2 * time.Second).Connect(context.Background())
+	if err != nil {
+		t.Fatalf("Expected Connect to succeed, got %v", err)
+	}
+	client.Close()
+}
+
+func TestHTTP2AdaptiveWindow(t *testing.T) {
+	builder := BuildFromStatic("http://127.0.0.1:1")
+	if opts := builder.windowOptions(); len(opts) != 0 {
+		t.Errorf("Expected gRPC default flow control, got %d options", len(opts))
+	}
+
+	builder.InitialStreamWindowSize(1 << 20).HTTP2AdaptiveWindow(true)
+	if opts := builder.windowOptions(); len(opts) != 0 {
+		t.Errorf("Expected adaptive window to drop static window sizes, got %d options", len(opts))
+	}
+
+	builder.HTTP2AdaptiveWindow(false)
+	if opts := builder.windowOptions(); len(opts) != 2 {
+		t.Errorf("Expected static connection and stream windows, got %d options", len(opts))
+	}
+
+	addr := startTestServer(t, &testGeyserServer{})
+	for _, enabled := range []bool{true, false} {
+		if err := getTestVersion(t, BuildFromStatic("http://"+addr).HTTP2AdaptiveWindow(enabled)); err != nil {
+			t.Fatalf("GetVersion with adaptive window %v failed: %v", enabled, err)
+		}
+	}
+}
@@ -1,5 +1,12 @@
 package yellowstone
 
+import (
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
 type GeyserGrpcClientError struct {
 	Type    string
 	Message string
@@ -33,6 +40,30 @@ func NewSubscribeSendError(err error) *GeyserGrpcClientError {
 	}
 }
 
+func NewInvalidSubscribeRequestError(err error) *GeyserGrpcClientError {
+	return &GeyserGrpcClientError{
+		Type:    "InvalidSubscribeRequest",
+		Message: "Subscribe request failed validation",
+		Err:     err,
+	}
+}
+
+func NewReconnectExhaustedError(err error) *GeyserGrpcClientError {
+	return &GeyserGrpcClientError{
+		Type:    "ReconnectExhausted",
+		Message: "Gave up reconnecting subscription",
+		Err:     err,
+	}
+}
+
+func NewPingTimeoutError(timeout time.Duration) *GeyserGrpcClientError {
+	return &GeyserGrpcClientError{
+		Type:    "PingTimeout",
+		Message: "No pong received within " + timeout.String(),
+		Err:     status.Error(codes.DeadlineExceeded, "subscribe stream ping timeout"),
+	}
+}
+
 type GeyserGrpcBuilderError struct {
 	Type    string
 	Message string
@@ -66,6 +97,46 @@ func NewTransportError(err error) *GeyserGrpcBuilderError {
 	}
 }
 
+func NewTLSConfigError(err error) *GeyserGrpcBuilderError {
+	return &GeyserGrpcBuilderError{
+		Type:    "TLSConfigError",
+		Message: "Invalid TLS configuration",
+		Err:     err,
+	}
+}
+
+func NewDialError(err error) *GeyserGrpcBuilderError {
+	return &GeyserGrpcBuilderError{
+		Type:    "DialError",
+		Message: "Failed to reach endpoint",
+		Err:     err,
+	}
+}
+
+func NewTLSError(err error) *GeyserGrpcBuilderError {
+	return &GeyserGrpcBuilderError{
+		Type:    "TLSError",
+		Message: "TLS handshake failed",
+		Err:     err,
+	}
+}
+
+func NewAuthenticationError(err error) *GeyserGrpcBuilderError {
+	return &GeyserGrpcBuilderError{
+		Type:    "AuthenticationError",
+		Message: "Endpoint rejected credentials",
+		Err:     err,
+	}
+}
+
+func NewProbeError(err error) *GeyserGrpcBuilderError {
+	return &GeyserGrpcBuilderError{
+		Type:    "ProbeError",
+		Message: "Connect probe failed",
+		Err:     err,
+	}
+}
+
 func NewInvalidUriError(uri string) *GeyserGrpcBuilderError {
 	return &GeyserGrpcBuilderError{
 		Type:    "InvalidUri",
@@ -8,7 +8,9 @@ import (
 	"time"
 
 	yellowstone "github.com/andrew-solarstorm/yellowstone-grpc-client-go"
+	"github.com/andrew-solarstorm/yellowstone-grpc-client-go/convert"
 	pb "github.com/andrew-solarstorm/yellowstone-grpc-client-go/proto"
+	spltoken "github.com/andrew-solarstorm/yellowstone-grpc-client-go/token"
 	"github.com/gagliardetto/solana-go"
 )
 
@@ -72,7 +74,8 @@ func TokenSniper(endpoint string, token string) {
 			accountUpdate := update.GetAccount()
 			account := accountUpdate.Account
 
-			if len(account.Data) >= 82 && !accountUpdate.IsStartup {
+			owner := solana.PublicKeyFromBytes(account.Owner)
+			if spltoken.DetectKind(owner, account.Data) == spltoken.KindMint && !accountUpdate.IsStartup {
 				mintPubkey := solana.PublicKeyFromBytes(account.Pubkey).String()
 
 				if !tokenCreations[mintPubkey] {
@@ -80,7 +83,7 @@ func TokenSniper(endpoint string, token string) {
 
 					fmt.Printf("🆕 NEW TOKEN DETECTED!\n")
 					fmt.Printf("   Mint: %s\n", mintPubkey)
-					fmt.Printf("   Owner: %s\n", solana.PublicKeyFromBytes(account.Owner).String())
+					fmt.Printf("   Owner: %s\n", owner.String())
 					fmt.Printf("   Slot: %d\n", accountUpdate.Slot)
 					fmt.Printf("   Data Size: %d bytes\n", len(account.Data))
 					fmt.Printf("   Timestamp: %s\n", time.Now().Format(time.RFC3339))
@@ -99,18 +102,22 @@ func TokenSniper(endpoint string, token string) {
 				isRaydium := false
 				hasTokenProgram := false
 
-				if tx.Transaction != nil && tx.Transaction.Message != nil {
-					for _, key := range tx.Transaction.Message.AccountKeys {
-						keyStr := solana.PublicKeyFromBytes(key).String()
-						if keyStr == PUMP_FUN_PROGRAM {
-							isPumpFun = true
-						}
-						if keyStr == RAYDIUM_AMM_V4 {
-							isRaydium = true
-						}
-						if keyStr == TOKEN_PROGRAM || keyStr == TOKEN_2022_PROGRAM {
-							hasTokenProgram = true
-						}
+				// Program IDs of v0 transactions may come from address lookup
+				// tables, so scan the full resolved key list.
+				var keys solana.AccountMetaSlice
+				if decoded, err := convert.TransactionInfo(tx); err == nil {
+					keys, _ = decoded.AccountKeys()
+				}
+				for _, key := range keys {
+					keyStr := key.PublicKey.String()
+					if keyStr == PUMP_FUN_PROGRAM {
+						isPumpFun = true
+					}
+					if keyStr == RAYDIUM_AMM_V4 {
+						isRaydium = true
+					}
+					if keyStr == TOKEN_PROGRAM || keyStr == TOKEN_2022_PROGRAM {
+						hasTokenProgram = true
 					}
 				}
 
@@ -126,7 +133,7 @@ func TokenSniper(endpoint string, token string) {
 					}
 
 					if tx.Transaction != nil && tx.Transaction.Message != nil {
-						fmt.Printf("   Accounts: %d\n", len(tx.Transaction.Message.AccountKeys))
+						fmt.Printf("   Accounts: %d\n", len(keys))
 						fmt.Printf("   Instructions: %d\n", len(tx.Transaction.Message.Instructions))
 					}
 
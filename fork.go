@@ -0,0 +1,274 @@
+package yellowstone
+
+import (
+	"sort"
+	"sync"
+
+	pb "github.com/andrew-solarstorm/yellowstone-grpc-client-go/proto"
+)
+
+const defaultForkWindow = 1024
+
+// ForkSlot is what a ForkTracker knows about one slot. Status is the latest
+// status received.
+type ForkSlot struct {
+	Slot      uint64
+	Parent    uint64
+	HasParent bool
+	Status    pb.SlotStatus
+	Processed bool
+	Confirmed bool
+	Dead      bool
+	DeadError string
+}
+
+// Rollback reports a slot that will never be finalized. Cause is the dead
+// slot it descends from, or the committed slot whose chain excludes it.
+// Processed tells whether updates of the slot may have been acted upon.
+type Rollback struct {
+	ForkSlot
+	Cause uint64
+}
+
+// ForkTracker keeps the tree of recent slots built from slot status updates.
+// A slot is rolled back when it or an ancestor is marked dead, or when a slot
+// reaches the rollback commitment on a chain that does not include it.
+// Rollbacks of one observation are reported newest slot first.
+type ForkTracker struct {
+	mu         sync.Mutex
+	slots      map[uint64]*forkNode
+	root       uint64
+	hasRoot    bool
+	highest    uint64
+	window     uint64
+	rollbackAt pb.SlotStatus
+	onRollback func(Rollback)
+}
+
+type forkNode struct {
+	ForkSlot
+	rolledBack bool
+	cause      uint64
+}
+
+func NewForkTracker() *ForkTracker {
+	return &ForkTracker{
+		slots:      make(map[uint64]*forkNode),
+		window:     defaultForkWindow,
+		rollbackAt: pb.SlotStatus_SLOT_FINALIZED,
+	}
+}
+
+func (f *ForkTracker) OnRollback(fn func(Rollback)) *ForkTracker {
+	f.onRollback = fn
+	return f
+}
+
+// RollbackAt sets the status, SLOT_CONFIRMED or SLOT_FINALIZED, at which
+// competing forks are treated as abandoned. Confirmed reports rollbacks
+// earlier at the small risk of reporting a fork that later wins.
+func (f *ForkTracker) RollbackAt(status pb.SlotStatus) *ForkTracker {
+	f.rollbackAt = status
+	return f
+}
+
+// Window bounds how many slots behind the highest slot are tracked when no
+// finalized slot prunes the tree.
+func (f *ForkTracker) Window(slots uint64) *ForkTracker {
+	f.window = slots
+	return f
+}
+
+func (f *ForkTracker) Handle(update *pb.SubscribeUpdate) error {
+	if slot := update.GetSlot(); slot != nil {
+		f.Observe(slot)
+	}
+	return nil
+}
+
+func (f *ForkTracker) Observe(update *pb.SubscribeUpdateSlot) {
+	f.mu.Lock()
+	rollbacks := f.observe(update)
+	f.mu.Unlock()
+
+	if f.onRollback == nil {
+		return
+	}
+	for _, rollback := range rollbacks {
+		f.onRollback(rollback)
+	}
+}
+
+func (f *ForkTracker) Slot(slot uint64) (ForkSlot, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	node, ok := f.slots[slot]
+	if !ok {
+		return ForkSlot{}, false
+	}
+	return node.ForkSlot, true
+}
+
+// Root returns the latest finalized slot.
+func (f *ForkTracker) Root() (uint64, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.root, f.hasRoot
+}
+
+func (f *ForkTracker) observe(update *pb.SubscribeUpdateSlot) []Rollback {
+	slot := update.GetSlot()
+	if f.hasRoot && slot < f.root {
+		return nil
+	}
+
+	node, ok := f.slots[slot]
+	if !ok {
+		node = &forkNode{ForkSlot: ForkSlot{Slot: slot}}
+		f.slots[slot] = node
+	}
+	if update.Parent != nil {
+		node.Parent = update.GetParent()
+		node.HasParent = true
+	}
+	node.Status = update.GetStatus()
+	if slot > f.highest {
+		f.highest = slot
+	}
+
+	var rollbacks []Rollback
+	switch update.GetStatus() {
+	case pb.SlotStatus_SLOT_PROCESSED:
+		node.Processed = true
+	case pb.SlotStatus_SLOT_CONFIRMED:
+		node.Processed = true
+		node.Confirmed = true
+		if f.rollbackAt == pb.SlotStatus_SLOT_CONFIRMED {
+			rollbacks = f.commit(slot)
+		}
+	case pb.SlotStatus_SLOT_FINALIZED:
+		node.Processed = true
+		node.Confirmed = true
+		rollbacks = f.commit(slot)
+		f.finalize(slot)
+	case pb.SlotStatus_SLOT_DEAD:
+		node.Dead = true
+		node.DeadError = update.GetDeadError()
+		rollbacks = f.rollbackBranch(slot, slot)
+	}
+
+	if !node.rolledBack && node.HasParent {
+		if parent, ok := f.slots[node.Parent]; ok && parent.rolledBack {
+			rollbacks = append(rollbacks, f.rollbackBranch(slot, parent.cause)...)
+		} else if f.hasRoot && slot > f.root && node.Parent < f.root {
+			rollbacks = append(rollbacks, f.rollbackBranch(slot, f.root)...)
+		}
+	}
+
+	f.prune()
+	sort.Slice(rollbacks, func(i, j int) bool { return rollbacks[i].Slot > rollbacks[j].Slot })
+	return rollbacks
+}
+
+// commit rolls back every tracked slot that is not on the chain ending at
+// anchor and cannot descend from it.
+func (f *ForkTracker) commit(anchor uint64) []Rollback {
+	chain := map[uint64]bool{anchor: true}
+	floor := anchor
+	for slot := anchor; ; {
+		node, ok := f.slots[slot]
+		if !ok || !node.HasParent || node.Parent >= slot {
+			break
+		}
+		slot = node.Parent
+		chain[slot] = true
+		floor = slot
+	}
+
+	var rollbacks []Rollback
+	for slot, node := range f.slots {
+		if node.rolledBack || chain[slot] {
+			continue
+		}
+		if slot < anchor {
+			// The chain passes over slot without including it.
+			if slot > floor {
+				rollbacks = append(rollbacks, f.rollback(node, anchor))
+			}
+			continue
+		}
+		if !f.descends(slot, anchor) {
+			rollbacks = append(rollbacks, f.rollback(node, anchor))
+		}
+	}
+	return rollbacks
+}
+
+// descends reports whether slot may descend from anchor. Ancestry that
+// leaves the tracked tree above anchor counts as possible.
+func (f *ForkTracker) descends(slot, anchor uint64) bool {
+	for slot > anchor {
+		node, ok := f.slots[slot]
+		if !ok || !node.HasParent {
+			return true
+		}
+		if node.rolledBack {
+			return false
+		}
+		slot = node.Parent
+	}
+	return slot == anchor
+}
+
+// rollbackBranch rolls back slot and every tracked descendant of it.
+func (f *ForkTracker) rollbackBranch(slot, cause uint64) []Rollback {
+	var rollbacks []Rollback
+	if node := f.slots[slot]; node != nil && !node.rolledBack {
+		rollbacks = append(rollbacks, f.rollback(node, cause))
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for _, node := range f.slots {
+			if node.rolledBack || !node.HasParent {
+				continue
+			}
+			if parent, ok := f.slots[node.Parent]; ok && parent.rolledBack && parent.cause == cause {
+				rollbacks = append(rollbacks, f.rollback(node, cause))
+				changed = true
+			}
+		}
+	}
+	return rollbacks
+}
+
+func (f *ForkTracker) rollback(node *forkNode, cause uint64) Rollback {
+	node.rolledBack = true
+	node.cause = cause
+	return Rollback{ForkSlot: node.ForkSlot, Cause: cause}
+}
+
+func (f *ForkTracker) finalize(slot uint64) {
+	if f.hasRoot && slot <= f.root {
+		return
+	}
+	f.root = slot
+	f.hasRoot = true
+	for s := range f.slots {
+		if s < slot {
+			delete(f.slots, s)
+		}
+	}
+}
+
+func (f *ForkTracker) prune() {
+	if f.highest <= f.window {
+		return
+	}
+	horizon := f.highest - f.window
+	for slot := range f.slots {
+		if slot < horizon {
+			delete(f.slots, slot)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package yellowstone
+
+import (
+	"slices"
+	"testing"
+
+	pb "github.com/andrew-solarstorm/yellowstone-grpc-client-go/proto"
+)
+
+func slotStatus(slot, parent uint64, status pb.SlotStatus) *pb.SubscribeUpdateSlot {
+	update := &pb.SubscribeUpdateSlot{Slot: slot, Status: status}
+	if parent > 0 {
+		update.Parent = &parent
+	}
+	return update
+}
+
+func rollbackSlots(rollbacks []Rollback) []uint64 {
+	slots := make([]uint64, len(rollbacks))
+	for i, rollback := range rollbacks {
+		slots[i] = rollback.Slot
+	}
+	return slots
+}
+
+func TestForkTrackerAbandonedFork(t *testing.T) {
+	var rollbacks []Rollback
+	tracker := NewForkTracker().OnRollback(func(r Rollback) { rollbacks = append(rollbacks, r) })
+
+	// 10 -> 11 -> 13 wins, 10 -> 12 -> 14 loses.
+	for _, update := range []*pb.SubscribeUpdateSlot{
+		slotStatus(10, 9, pb.SlotStatus_SLOT_PROCESSED),
+		slotStatus(11, 10, pb.SlotStatus_SLOT_PROCESSED),
+		slotStatus(12, 10, pb.SlotStatus_SLOT_PROCESSED),
+		slotStatus(13, 11, pb.SlotStatus_SLOT_PROCESSED),
+		slotStatus(14, 12, pb.SlotStatus_SLOT_PROCESSED),
+		slotStatus(13, 0, pb.SlotStatus_SLOT_CONFIRMED),
+	} {
+		tracker.Observe(update)
+	}
+	if len(rollbacks) != 0 {
+		t.Fatalf("Confirmation should not roll back by default, got %v", rollbackSlots(rollbacks))
+	}
+
+	tracker.Observe(slotStatus(13, 0, pb.SlotStatus_SLOT_FINALIZED))
+	if got := rollbackSlots(rollbacks); !slices.Equal(got, []uint64{14, 12}) {
+		t.Fatalf("Expected rollbacks [14 12], got %v", got)
+	}
+	for _, rollback := range rollbacks {
+		if rollback.Cause != 13 || !rollback.Processed {
+			t.Fatalf("Unexpected rollback: %+v", rollback)
+		}
+	}
+	if root, ok := tracker.Root(); !ok || root != 13 {
+		t.Fatalf("Expected root 13, got %d", root)
+	}
+	if _, ok := tracker.Slot(11); ok {
+		t.Fatal("Slots below the root should be pruned")
+	}
+
+	rollbacks = nil
+	tracker.Observe(slotStatus(15, 12, pb.SlotStatus_SLOT_CREATED_BANK))
+	if got := rollbackSlots(rollbacks); !slices.Equal(got, []uint64{15}) {
+		t.Fatalf("Child of a pruned fork should roll back, got %v", got)
+	}
+}
+
+func TestForkTrackerDeadSlot(t *testing.T) {
+	var rollbacks []Rollback
+	tracker := NewForkTracker().OnRollback(func(r Rollback) { rollbacks = append(rollbacks, r) })
+
+	tracker.Observe(slotStatus(20, 19, pb.SlotStatus_SLOT_PROCESSED))
+	tracker.Observe(slotStatus(21, 20, pb.SlotStatus_SLOT_CREATED_BANK))
+	tracker.Observe(slotStatus(22, 21, pb.SlotStatus_SLOT_CREATED_BANK))
+
+	dead := slotStatus(21, 0, pb.SlotStatus_SLOT_DEAD)
+	reason := "invalid block"
+	dead.DeadError = &reason
+	tracker.Observe(dead)
+
+	if got := rollbackSlots(rollbacks); !slices.Equal(got, []uint64{22, 21}) {
+		t.Fatalf("Expected rollbacks [22 21], got %v", got)
+	}
+	if r := rollbacks[1]; !r.Dead || r.DeadError != reason || r.Cause != 21 || r.Processed {
+		t.Fatalf("Unexpected dead rollback: %+v", r)
+	}
+
+	rollbacks = nil
+	tracker.Observe(slotStatus(23, 22, pb.SlotStatus_SLOT_CREATED_BANK))
+	if got := rollbackSlots(rollbacks); !slices.Equal(got, []uint64{23}) || rollbacks[0].Cause != 21 {
+		t.Fatalf("Descendant of a dead slot should roll back, got %+v", rollbacks)
+	}
+}
+
+func TestForkTrackerRollbackAtConfirmed(t *testing.T) {
+	var rollbacks []Rollback
+	tracker := NewForkTracker().
+		RollbackAt(pb.SlotStatus_SLOT_CONFIRMED).
+		OnRollback(func(r Rollback) { rollbacks = append(rollbacks, r) })
+
+	tracker.Observe(slotStatus(30, 29, pb.SlotStatus_SLOT_PROCESSED))
+	tracker.Observe(slotStatus(31, 30, pb.SlotStatus_SLOT_PROCESSED))
+	tracker.Observe(slotStatus(32, 30, pb.SlotStatus_SLOT_PROCESSED))
+	tracker.Observe(slotStatus(33, 0, pb.SlotStatus_SLOT_FIRST_SHRED_RECEIVED))
+	tracker.Observe(slotStatus(32, 0, pb.SlotStatus_SLOT_CONFIRMED))
+
+	if got := rollbackSlots(rollbacks); !slices.Equal(got, []uint64{31}) {
+		t.Fatalf("Expected rollback of 31, got %v", got)
+	}
+	if slot, ok := tracker.Slot(32); !ok || !slot.Confirmed || slot.Status != pb.SlotStatus_SLOT_CONFIRMED {
+		t.Fatalf("Unexpected slot state: %+v", slot)
+	}
+}
@@ -0,0 +1,666 @@
+package yellowstone
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/andrew-solarstorm/yellowstone-grpc-client-go/proto"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	ErrBackfillUnavailable = errors.New("server keeps no replay history")
+	ErrBackfillTimeout     = errors.New("backfill timed out")
+	ErrBackfillOverflow    = errors.New("backfill abandoned: live update buffer full")
+
+	errBackfillDone = errors.New("backfill done")
+)
+
+const (
+	defaultGapWindow       = 512
+	defaultMaxBackfill     = 64
+	defaultBackfillTimeout = 10 * time.Second
+	defaultMaxBuffered     = 10000
+)
+
+// Gap describes a discontinuity in the slot chain, found when Slot arrived
+// with a Parent other than the previous slot. Skipped slots were never
+// produced by their leader; Missing ones were on the chain but not received.
+// When the chain below a missing slot is unknown, the remaining slots of the
+// range count as missing too.
+type Gap struct {
+	Slot   uint64
+	Parent uint64
+	// From and To bound the range of slots without any update that ends at
+	// Parent. Both are zero when Parent was received.
+	From      uint64
+	To        uint64
+	Skipped   []uint64
+	Missing   []uint64
+	Recovered []uint64
+	Err       error
+}
+
+// GapDetector tracks slot continuity through the parent links carried by slot,
+// block and block meta updates. When the parent of a new slot was never
+// received it opens a temporary stream with FromSlot to backfill the missing
+// range and delivers the recovered updates, in slot order, before the update
+// that revealed the gap. The request must include a slots, blocks or blocks
+// meta filter for gaps to be seen.
+//
+// The backfill runs beside the live stream, which keeps being read so the
+// server does not drop it as a slow consumer. Live updates are buffered until
+// the backfill ends, bounded by the backfill timeout and MaxBuffered, and
+// delivered after the recovered ones.
+type GapDetector struct {
+	client  *GeyserGrpcClient
+	request *pb.SubscribeRequest
+	policy  *ReconnectPolicy
+
+	backfill        bool
+	backfillTimeout time.Duration
+	maxBackfill     uint64
+	window          uint64
+	maxBuffered     int
+	onGap           func(Gap)
+}
+
+func NewGapDetector(client *GeyserGrpcClient, request *pb.SubscribeRequest) *GapDetector {
+	if request == nil {
+		request = &pb.SubscribeRequest{}
+	}
+	return &GapDetector{
+		client:          client,
+		request:         proto.Clone(request).(*pb.SubscribeRequest),
+		backfill:        true,
+		backfillTimeout: defaultBackfillTimeout,
+		maxBackfill:     defaultMaxBackfill,
+		window:          defaultGapWindow,
+		maxBuffered:     defaultMaxBuffered,
+	}
+}
+
+func (d *GapDetector) Backfill(enabled bool) *GapDetector {
+	d.backfill = enabled
+	return d
+}
+
+// BackfillTimeout bounds each backfill, and so how long live updates are
+// held back behind it.
+func (d *GapDetector) BackfillTimeout(timeout time.Duration) *GapDetector {
+	d.backfillTimeout = timeout
+	return d
+}
+
+// MaxBuffered bounds the live updates held back behind a backfill. When the
+// buffer fills the backfill is abandoned with ErrBackfillOverflow, keeping
+// what it recovered so far, and the live stream waits while the buffer
+// drains. Zero or less means no bound.
+func (d *GapDetector) MaxBuffered(updates int) *GapDetector {
+	d.maxBuffered = updates
+	return d
+}
+
+// MaxBackfillSlots bounds how many slots a single backfill may cover.
+func (d *GapDetector) MaxBackfillSlots(slots uint64) *GapDetector {
+	d.maxBackfill = slots
+	return d
+}
+
+// Window sets how many slots behind the highest seen slot are tracked.
+// Parents older than that are assumed to have been received.
+func (d *GapDetector) Window(slots uint64) *GapDetector {
+	d.window = slots
+	return d
+}
+
+func (d *GapDetector) OnGap(fn func(Gap)) *GapDetector {
+	d.onGap = fn
+	return d
+}
+
+func (d *GapDetector) SetReconnectPolicy(policy ReconnectPolicy) *GapDetector {
+	d.policy = &policy
+	return d
+}
+
+// Run streams the request and calls fn with every update, including those
+// recovered by backfill. Calls to fn and OnGap are serialised.
+func (d *GapDetector) Run(ctx context.Context, fn func(*pb.SubscribeUpdate) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	r := &gapRun{
+		detector: d,
+		ctx:      ctx,
+		cancel:   cancel,
+		fn:       fn,
+		chain:    newSlotChain(d.window),
+	}
+	r.cond = sync.NewCond(&r.mu)
+
+	var err error
+	if d.policy != nil {
+		err = d.client.SubscribeWithReconnect(d.request, *d.policy).Run(ctx, r.handle)
+	} else {
+		err = d.client.runStream(ctx, d.request, r.handle)
+	}
+	r.wg.Wait()
+
+	if r.err != nil {
+		return r.err
+	}
+	return err
+}
+
+// gapRun is the state of one GapDetector.Run. mu guards the chain, the
+// pending backfill and err; fn is called without it. While a backfill is
+// pending the backfill goroutine is the only caller of fn and the live
+// stream only buffers, otherwise the live stream calls fn itself.
+type gapRun struct {
+	detector *GapDetector
+	ctx      context.Context
+	cancel   context.CancelFunc
+	fn       func(*pb.SubscribeUpdate) error
+	wg       sync.WaitGroup
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	chain   *slotChain
+	pending *pendingBackfill
+	err     error
+}
+
+// pendingBackfill is a gap being backfilled, with the update that revealed
+// it and the live updates received since. draining is set once the backfill
+// ended and its updates are being delivered.
+type pendingBackfill struct {
+	gap      Gap
+	update   *pb.SubscribeUpdate
+	buffered []*pb.SubscribeUpdate
+	cancel   context.CancelFunc
+	overflow bool
+	draining bool
+}
+
+func (r *gapRun) handle(update *pb.SubscribeUpdate) error {
+	r.mu.Lock()
+	for {
+		if r.err != nil {
+			r.mu.Unlock()
+			return r.err
+		}
+		p := r.pending
+		if p == nil {
+			break
+		}
+		if max := r.detector.maxBuffered; max <= 0 || len(p.buffered) < max {
+			p.buffered = append(p.buffered, update)
+			r.mu.Unlock()
+			return nil
+		}
+		// The buffer is full: give up on the backfill and wait for the
+		// drain to make room.
+		if !p.draining && !p.overflow {
+			p.overflow = true
+			p.cancel()
+		}
+		r.cond.Wait()
+	}
+	gap, deliver := r.process(update)
+	r.mu.Unlock()
+	return r.deliver(gap, update, deliver)
+}
+
+// process updates the chain with update. It returns the gap to report and
+// whether update is to be delivered now; it is not when update opened a
+// backfill, which delivers it. Called with mu held.
+func (r *gapRun) process(update *pb.SubscribeUpdate) (*Gap, bool) {
+	d := r.detector
+	slot, parent, ok := updateParent(update)
+	if !ok || !r.chain.link(slot, parent) {
+		r.chain.see(update)
+		return nil, true
+	}
+
+	if !r.chain.unknown(parent) {
+		skipped := r.chain.skip(parent, slot)
+		r.chain.see(update)
+		if len(skipped) == 0 {
+			return nil, true
+		}
+		return &Gap{Slot: slot, Parent: parent, Skipped: skipped}, true
+	}
+
+	from := parent
+	for from > 0 && parent-from+1 < d.maxBackfill && r.chain.unknown(from-1) {
+		from--
+	}
+	gap := Gap{Slot: slot, Parent: parent, From: from, To: parent}
+
+	if !d.backfill {
+		r.classify(&gap)
+		r.chain.see(update)
+		return &gap, true
+	}
+
+	ctx, cancel := context.WithCancel(r.ctx)
+	pending := &pendingBackfill{gap: gap, update: update, cancel: cancel}
+	r.pending = pending
+	r.wg.Add(1)
+	go r.runBackfill(ctx, pending)
+	return nil, false
+}
+
+func (r *gapRun) deliver(gap *Gap, update *pb.SubscribeUpdate, deliver bool) error {
+	if gap != nil && r.detector.onGap != nil {
+		r.detector.onGap(*gap)
+	}
+	if !deliver {
+		return nil
+	}
+	return r.fn(update)
+}
+
+func (r *gapRun) runBackfill(ctx context.Context, pending *pendingBackfill) {
+	defer r.wg.Done()
+	defer pending.cancel()
+	gap := &pending.gap
+	recovered, err := r.detector.runBackfill(ctx, gap.From, gap.To, gap.Slot)
+
+	r.mu.Lock()
+	if r.err != nil || r.ctx.Err() != nil {
+		r.pending = nil
+		r.cond.Broadcast()
+		r.mu.Unlock()
+		return
+	}
+	if pending.overflow {
+		err = ErrBackfillOverflow
+	}
+	gap.Err = err
+	pending.draining = true
+	for _, update := range recovered {
+		if slot, parent, ok := updateParent(update); ok {
+			r.chain.link(slot, parent)
+		}
+		r.chain.see(update)
+	}
+	r.classify(gap)
+	r.chain.see(pending.update)
+	r.mu.Unlock()
+
+	for _, update := range recovered {
+		if err := r.deliver(nil, update, true); err != nil {
+			r.fail(err)
+			return
+		}
+	}
+	if err := r.deliver(gap, pending.update, true); err != nil {
+		r.fail(err)
+		return
+	}
+	r.drain(pending)
+}
+
+// drain delivers the live updates buffered behind a backfill, then hands
+// delivery back to the live stream. One of them may reveal another gap,
+// whose backfill takes over the rest of the buffer.
+func (r *gapRun) drain(pending *pendingBackfill) {
+	for {
+		r.mu.Lock()
+		if r.err != nil {
+			r.mu.Unlock()
+			return
+		}
+		if len(pending.buffered) == 0 {
+			r.pending = nil
+			r.cond.Broadcast()
+			r.mu.Unlock()
+			return
+		}
+		update := pending.buffered[0]
+		pending.buffered = pending.buffered[1:]
+		r.cond.Broadcast()
+
+		r.pending = nil
+		gap, deliver := r.process(update)
+		if next := r.pending; next != nil {
+			next.buffered = pending.buffered
+			r.mu.Unlock()
+			return
+		}
+		r.pending = pending
+		r.mu.Unlock()
+
+		if err := r.deliver(gap, update, deliver); err != nil {
+			r.fail(err)
+			return
+		}
+	}
+}
+
+func (r *gapRun) fail(err error) {
+	r.mu.Lock()
+	if r.err == nil {
+		r.err = err
+	}
+	r.pending = nil
+	r.cond.Broadcast()
+	r.mu.Unlock()
+	r.cancel()
+}
+
+// runBackfill replays from FromSlot and returns the updates of slots in
+// [from, to], sorted by slot, once the stream reaches until.
+func (d *GapDetector) runBackfill(ctx context.Context, from, to, until uint64) ([]*pb.SubscribeUpdate, error) {
+	point, err := d.client.ResumeFrom(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	if point.FromSlot == nil {
+		return nil, ErrBackfillUnavailable
+	}
+
+	request := d.backfillRequest()
+	point.Apply(request)
+
+	ctx, cancel := context.WithTimeout(ctx, d.backfillTimeout)
+	defer cancel()
+
+	var recovered []*pb.SubscribeUpdate
+	err = d.client.runStream(ctx, request, func(update *pb.SubscribeUpdate) error {
+		slot, ok := UpdateSlot(update)
+		if !ok {
+			return nil
+		}
+		if slot >= until {
+			return errBackfillDone
+		}
+		if slot >= from && slot <= to {
+			recovered = append(recovered, update)
+		}
+		return nil
+	})
+	if err == nil && ctx.Err() != nil {
+		err = ErrBackfillTimeout
+	}
+
+	recovered = d.backfillBlocks(recovered)
+	sort.SliceStable(recovered, func(i, j int) bool {
+		a, _ := UpdateSlot(recovered[i])
+		b, _ := UpdateSlot(recovered[j])
+		return a < b
+	})
+
+	if errors.Is(err, errBackfillDone) {
+		return recovered, nil
+	}
+	return recovered, err
+}
+
+// backfillBlockPrefix marks the filters that replay a blocks filter, so their
+// updates can be told apart from those of the request's own filters.
+const backfillBlockPrefix = "backfill-block:"
+
+// backfillRequest adapts the request to FromSlot, which cannot replay block
+// or entry filters. Each blocks filter is replayed as block meta plus the
+// transactions and accounts it includes, which backfillBlocks reassembles.
+func (d *GapDetector) backfillRequest() *pb.SubscribeRequest {
+	request := proto.Clone(d.request).(*pb.SubscribeRequest)
+	for name, filter := range request.Blocks {
+		name = backfillBlockPrefix + name
+		if request.BlocksMeta == nil {
+			request.BlocksMeta = make(map[string]*pb.SubscribeRequestFilterBlocksMeta)
+		}
+		request.BlocksMeta[name] = &pb.SubscribeRequestFilterBlocksMeta{}
+
+		if filter.IncludeTransactions == nil || filter.GetIncludeTransactions() {
+			if request.Transactions == nil {
+				request.Transactions = make(map[string]*pb.SubscribeRequestFilterTransactions)
+			}
+			request.Transactions[name] = &pb.SubscribeRequestFilterTransactions{AccountInclude: filter.AccountInclude}
+		}
+
+		if filter.GetIncludeAccounts() {
+			if request.Accounts == nil {
+				request.Accounts = make(map[string]*pb.SubscribeRequestFilterAccounts)
+			}
+			request.Accounts[name] = &pb.SubscribeRequestFilterAccounts{Account: filter.AccountInclude}
+		}
+	}
+	request.Blocks = nil
+	request.Entry = nil
+	return request
+}
+
+// backfillBlocks turns the updates replayed for each blocks filter back into
+// one block update per slot and filter. Slots whose block meta was not
+// replayed cannot be rebuilt and are left out. Entries cannot be replayed, so
+// rebuilt blocks carry none.
+func (d *GapDetector) backfillBlocks(updates []*pb.SubscribeUpdate) []*pb.SubscribeUpdate {
+	if len(d.request.Blocks) == 0 {
+		return updates
+	}
+
+	type blockKey struct {
+		slot uint64
+		name string
+	}
+	var (
+		order  []blockKey
+		blocks = make(map[blockKey]*pendingBlock)
+		metas  = make(map[blockKey]*pb.SubscribeUpdate)
+		out    = make([]*pb.SubscribeUpdate, 0, len(updates))
+	)
+	for _, update := range updates {
+		var own []string
+		for _, filter := range update.Filters {
+			name, ok := strings.CutPrefix(filter, backfillBlockPrefix)
+			if !ok {
+				own = append(own, filter)
+				continue
+			}
+			slot, ok := assemblySlot(update)
+			if !ok {
+				continue
+			}
+			key := blockKey{slot: slot, name: name}
+			block, ok := blocks[key]
+			if !ok {
+				block = newPendingBlock(slot, time.Time{})
+				blocks[key] = block
+				order = append(order, key)
+			}
+			block.add(update)
+			if update.GetBlockMeta() != nil {
+				metas[key] = update
+			}
+		}
+
+		switch {
+		case len(own) == len(update.Filters):
+			out = append(out, update)
+		case len(own) > 0:
+			update = proto.Clone(update).(*pb.SubscribeUpdate)
+			update.Filters = own
+			out = append(out, update)
+		}
+	}
+
+	for _, key := range order {
+		meta, ok := metas[key]
+		if !ok {
+			continue
+		}
+		out = append(out, &pb.SubscribeUpdate{
+			Filters:     []string{key.name},
+			CreatedAt:   meta.CreatedAt,
+			UpdateOneof: &pb.SubscribeUpdate_Block{Block: blocks[key].assemble()},
+		})
+	}
+	return out
+}
+
+// classify walks the parent chain down from the gap's parent. Slots on the
+// chain are recovered or missing, the others in the range without updates
+// were skipped. Every slot of the range is settled so it is reported once.
+func (r *gapRun) classify(gap *Gap) {
+	onChain := make(map[uint64]bool)
+	slot := gap.Parent
+	for slot >= gap.From {
+		if !r.chain.received(slot) {
+			for s := gap.From; s <= slot; s++ {
+				if !r.chain.received(s) {
+					gap.Missing = append(gap.Missing, s)
+					onChain[s] = true
+				}
+			}
+			break
+		}
+		gap.Recovered = append(gap.Recovered, slot)
+		onChain[slot] = true
+
+		parent, ok := r.chain.parents[slot]
+		if !ok || parent >= slot {
+			break
+		}
+		slot = parent
+	}
+	slices.Sort(gap.Recovered)
+
+	for s := gap.From; s <= gap.To; s++ {
+		if !onChain[s] && !r.chain.received(s) {
+			gap.Skipped = append(gap.Skipped, s)
+		}
+		r.chain.settle(s)
+	}
+	gap.Skipped = append(gap.Skipped, r.chain.skip(gap.Parent, gap.Slot)...)
+}
+
+func updateParent(update *pb.SubscribeUpdate) (slot uint64, parent uint64, ok bool) {
+	switch u := update.GetUpdateOneof().(type) {
+	case *pb.SubscribeUpdate_Slot:
+		if u.Slot.Parent == nil {
+			return 0, 0, false
+		}
+		return u.Slot.GetSlot(), u.Slot.GetParent(), true
+	case *pb.SubscribeUpdate_Block:
+		return u.Block.GetSlot(), u.Block.GetParentSlot(), true
+	case *pb.SubscribeUpdate_BlockMeta:
+		return u.BlockMeta.GetSlot(), u.BlockMeta.GetParentSlot(), true
+	default:
+		return 0, 0, false
+	}
+}
+
+// slotChain remembers which recent slots had updates and their parent links.
+// Slots at or below the first one seen, and those that fell out of the window,
+// are never reported as unknown.
+type slotChain struct {
+	window  uint64
+	started bool
+	floor   uint64
+	highest uint64
+	seen    map[uint64]struct{}
+	settled map[uint64]struct{}
+	parents map[uint64]uint64
+}
+
+func newSlotChain(window uint64) *slotChain {
+	return &slotChain{
+		window:  window,
+		seen:    make(map[uint64]struct{}),
+		settled: make(map[uint64]struct{}),
+		parents: make(map[uint64]uint64),
+	}
+}
+
+func (c *slotChain) see(update *pb.SubscribeUpdate) {
+	slot, ok := UpdateSlot(update)
+	if !ok {
+		return
+	}
+	if !c.started {
+		c.started = true
+		c.floor = slot
+		c.highest = slot
+	}
+	c.seen[slot] = struct{}{}
+	if slot > c.highest {
+		c.highest = slot
+		c.prune()
+	}
+}
+
+// link records the parent of slot and reports whether it is new.
+func (c *slotChain) link(slot, parent uint64) bool {
+	if !c.started {
+		c.started = true
+		c.floor = slot
+		c.highest = slot
+	}
+	if _, ok := c.parents[slot]; ok {
+		return false
+	}
+	c.parents[slot] = parent
+	return true
+}
+
+// skip settles the slots between parent and slot that had no updates and
+// returns them.
+func (c *slotChain) skip(parent, slot uint64) []uint64 {
+	var skipped []uint64
+	for s := parent + 1; s < slot && slot-s <= c.window; s++ {
+		if _, ok := c.seen[s]; ok {
+			continue
+		}
+		c.settle(s)
+		skipped = append(skipped, s)
+	}
+	return skipped
+}
+
+// settle marks a slot without updates as accounted for, either skipped or
+// already reported missing.
+func (c *slotChain) settle(slot uint64) {
+	c.settled[slot] = struct{}{}
+}
+
+func (c *slotChain) received(slot uint64) bool {
+	_, ok := c.seen[slot]
+	return ok
+}
+
+func (c *slotChain) unknown(slot uint64) bool {
+	if slot <= c.floor || c.highest > c.window && slot < c.highest-c.window {
+		return false
+	}
+	_, seen := c.seen[slot]
+	_, settled := c.settled[slot]
+	return !seen && !settled
+}
+
+func (c *slotChain) prune() {
+	if c.highest <= c.window {
+		return
+	}
+	horizon := c.highest - c.window
+	for _, m := range []map[uint64]struct{}{c.seen, c.settled} {
+		for slot := range m {
+			if slot < horizon {
+				delete(m, slot)
+			}
+		}
+	}
+	for slot := range c.parents {
+		if slot < horizon {
+			delete(c.parents, slot)
+		}
+	}
+}
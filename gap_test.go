@@ -0,0 +1,277 @@
+package yellowstone
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	pb "github.com/andrew-solarstorm/yellowstone-grpc-client-go/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func blockMetaUpdate(slot, parent uint64) *pb.SubscribeUpdate {
+	return &pb.SubscribeUpdate{
+		UpdateOneof: &pb.SubscribeUpdate_BlockMeta{
+			BlockMeta: &pb.SubscribeUpdateBlockMeta{Slot: slot, ParentSlot: parent},
+		},
+	}
+}
+
+func transactionStatusUpdate(slot uint64) *pb.SubscribeUpdate {
+	return &pb.SubscribeUpdate{
+		UpdateOneof: &pb.SubscribeUpdate_TransactionStatus{
+			TransactionStatus: &pb.SubscribeUpdateTransactionStatus{Slot: slot},
+		},
+	}
+}
+
+func blockUpdate(slot, parent uint64) *pb.SubscribeUpdate {
+	return &pb.SubscribeUpdate{
+		UpdateOneof: &pb.SubscribeUpdate_Block{
+			Block: &pb.SubscribeUpdateBlock{Slot: slot, ParentSlot: parent},
+		},
+	}
+}
+
+// gapServer streams main to live subscriptions and replay to FromSlot ones.
+// The replay waits until main has been sent, so updates after a gap reach
+// the client while its backfill is still running.
+func gapServer(main, replay []*pb.SubscribeUpdate) *testGeyserServer {
+	first := uint64(1)
+	sent := make(chan struct{})
+	var once sync.Once
+	return &testGeyserServer{
+		replayInfo: func(context.Context) (*pb.SubscribeReplayInfoResponse, error) {
+			return &pb.SubscribeReplayInfoResponse{FirstAvailable: &first}, nil
+		},
+		subscribe: func(stream pbSubscribeServer) error {
+			req, err := stream.Recv()
+			if err != nil {
+				return err
+			}
+			updates := main
+			if req.FromSlot != nil {
+				if len(req.Blocks) > 0 || len(req.Entry) > 0 {
+					return status.Error(codes.InvalidArgument, "from_slot with blocks or entries")
+				}
+				<-sent
+				updates = replay
+			}
+			for _, update := range updates {
+				if err := stream.Send(update); err != nil {
+					return err
+				}
+			}
+			if req.FromSlot != nil {
+				<-stream.Context().Done()
+			} else {
+				once.Do(func() { close(sent) })
+			}
+			return nil
+		},
+	}
+}
+
+func runGapDetector(t *testing.T, detector *GapDetector) ([]uint64, []Gap) {
+	t.Helper()
+
+	var (
+		slots []uint64
+		gaps  []Gap
+	)
+	detector.OnGap(func(gap Gap) { gaps = append(gaps, gap) })
+	err := detector.Run(context.Background(), func(update *pb.SubscribeUpdate) error {
+		slot, _ := UpdateSlot(update)
+		slots = append(slots, slot)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	return slots, gaps
+}
+
+func TestGapDetectorBackfill(t *testing.T) {
+	main := []*pb.SubscribeUpdate{
+		blockMetaUpdate(10, 9),
+		blockMetaUpdate(11, 10),
+		blockMetaUpdate(14, 13),
+		blockMetaUpdate(15, 14),
+	}
+	replay := []*pb.SubscribeUpdate{
+		transactionStatusUpdate(13),
+		blockMetaUpdate(13, 11),
+		blockMetaUpdate(14, 13),
+	}
+	client := connectTestClient(t, startTestServer(t, gapServer(main, replay)))
+
+	slots, gaps := runGapDetector(t, NewGapDetector(client, &pb.SubscribeRequest{
+		BlocksMeta: map[string]*pb.SubscribeRequestFilterBlocksMeta{"meta": {}},
+	}))
+
+	if want := []uint64{10, 11, 13, 13, 14, 15}; !slices.Equal(slots, want) {
+		t.Fatalf("Expected slots %v, got %v", want, slots)
+	}
+	if len(gaps) != 1 {
+		t.Fatalf("Expected one gap, got %+v", gaps)
+	}
+	gap := gaps[0]
+	if gap.Err != nil || gap.From != 12 || gap.To != 13 {
+		t.Fatalf("Unexpected gap: %+v", gap)
+	}
+	if !slices.Equal(gap.Recovered, []uint64{13}) || !slices.Equal(gap.Skipped, []uint64{12}) || len(gap.Missing) != 0 {
+		t.Fatalf("Unexpected classification: %+v", gap)
+	}
+}
+
+func TestGapDetectorBackfillBlocks(t *testing.T) {
+	main := []*pb.SubscribeUpdate{
+		blockUpdate(10, 9),
+		blockUpdate(11, 10),
+		blockUpdate(14, 13),
+		blockUpdate(15, 14),
+		blockUpdate(17, 15),
+	}
+	replayed := func(update *pb.SubscribeUpdate) *pb.SubscribeUpdate {
+		update.Filters = []string{backfillBlockPrefix + "blocks"}
+		return update
+	}
+	meta := blockMetaUpdate(13, 11)
+	meta.GetBlockMeta().ExecutedTransactionCount = 1
+	replay := []*pb.SubscribeUpdate{
+		replayed(&pb.SubscribeUpdate{UpdateOneof: &pb.SubscribeUpdate_Transaction{Transaction: &pb.SubscribeUpdateTransaction{
+			Slot:        13,
+			Transaction: &pb.SubscribeUpdateTransactionInfo{Signature: []byte{1}},
+		}}}),
+		replayed(meta),
+		replayed(blockMetaUpdate(14, 13)),
+	}
+	client := connectTestClient(t, startTestServer(t, gapServer(main, replay)))
+
+	detector := NewGapDetector(client, &pb.SubscribeRequest{
+		Blocks: map[string]*pb.SubscribeRequestFilterBlocks{"blocks": {}},
+	})
+
+	var (
+		slots []uint64
+		gaps  []Gap
+		block *pb.SubscribeUpdate
+		other []*pb.SubscribeUpdate
+	)
+	detector.OnGap(func(gap Gap) { gaps = append(gaps, gap) })
+	err := detector.Run(context.Background(), func(update *pb.SubscribeUpdate) error {
+		if update.GetBlock() == nil {
+			other = append(other, update)
+			return nil
+		}
+		if update.GetBlock().GetSlot() == 13 {
+			block = update
+		}
+		slots = append(slots, update.GetBlock().GetSlot())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if len(other) != 0 {
+		t.Fatalf("Expected only block updates, got %v", other)
+	}
+	if want := []uint64{10, 11, 13, 14, 15, 17}; !slices.Equal(slots, want) {
+		t.Fatalf("Expected slots %v, got %v", want, slots)
+	}
+	if got := block.GetBlock(); got.GetParentSlot() != 11 || len(got.GetTransactions()) != 1 || !slices.Equal(block.Filters, []string{"blocks"}) {
+		t.Fatalf("Unexpected rebuilt block: %v", block)
+	}
+	if len(gaps) != 2 {
+		t.Fatalf("Expected two gaps, got %+v", gaps)
+	}
+	if gap := gaps[0]; gap.Err != nil || !slices.Equal(gap.Recovered, []uint64{13}) {
+		t.Fatalf("Expected slot 13 to be recovered, got %+v", gap)
+	}
+	if skip := gaps[1]; !slices.Equal(skip.Skipped, []uint64{16}) {
+		t.Fatalf("Expected a leader skip after the backfill, got %+v", skip)
+	}
+
+	request := detector.backfillRequest()
+	name := backfillBlockPrefix + "blocks"
+	if len(request.Blocks) != 0 || request.BlocksMeta[name] == nil || request.Transactions[name] == nil {
+		t.Fatalf("Expected blocks to be replayed as block meta and transactions, got %v", request)
+	}
+	if err := ValidateSubscribeRequest(request); err != nil {
+		t.Fatalf("Backfill request is invalid: %v", err)
+	}
+}
+
+func TestGapDetectorBufferOverflow(t *testing.T) {
+	main := []*pb.SubscribeUpdate{
+		blockMetaUpdate(10, 9),
+		blockMetaUpdate(11, 10),
+		blockMetaUpdate(14, 13),
+		blockMetaUpdate(15, 14),
+		blockMetaUpdate(16, 15),
+		blockMetaUpdate(17, 16),
+	}
+	// The replay never reaches slot 14, so only the overflow ends it.
+	client := connectTestClient(t, startTestServer(t, gapServer(main, nil)))
+
+	slots, gaps := runGapDetector(t, NewGapDetector(client, &pb.SubscribeRequest{
+		BlocksMeta: map[string]*pb.SubscribeRequestFilterBlocksMeta{"meta": {}},
+	}).MaxBuffered(1).BackfillTimeout(time.Minute))
+
+	if want := []uint64{10, 11, 14, 15, 16, 17}; !slices.Equal(slots, want) {
+		t.Fatalf("Expected slots %v, got %v", want, slots)
+	}
+	if len(gaps) != 1 || !errors.Is(gaps[0].Err, ErrBackfillOverflow) {
+		t.Fatalf("Expected an abandoned backfill, got %+v", gaps)
+	}
+	if !slices.Equal(gaps[0].Missing, []uint64{12, 13}) {
+		t.Fatalf("Expected slots 12 and 13 to be missing, got %+v", gaps[0])
+	}
+}
+
+func TestGapDetectorWithoutBackfill(t *testing.T) {
+	main := []*pb.SubscribeUpdate{
+		blockMetaUpdate(10, 9),
+		blockMetaUpdate(13, 10),
+		blockMetaUpdate(16, 15),
+	}
+	client := connectTestClient(t, startTestServer(t, gapServer(main, nil)))
+
+	slots, gaps := runGapDetector(t, NewGapDetector(client, &pb.SubscribeRequest{
+		BlocksMeta: map[string]*pb.SubscribeRequestFilterBlocksMeta{"meta": {}},
+	}).Backfill(false))
+
+	if want := []uint64{10, 13, 16}; !slices.Equal(slots, want) {
+		t.Fatalf("Expected slots %v, got %v", want, slots)
+	}
+	if len(gaps) != 2 {
+		t.Fatalf("Expected two gaps, got %+v", gaps)
+	}
+	if skip := gaps[0]; !slices.Equal(skip.Skipped, []uint64{11, 12}) || len(skip.Missing) != 0 {
+		t.Fatalf("Expected a leader skip, got %+v", skip)
+	}
+	if miss := gaps[1]; !slices.Equal(miss.Missing, []uint64{14, 15}) || len(miss.Recovered) != 0 {
+		t.Fatalf("Expected missing slots, got %+v", miss)
+	}
+}
+
+func TestSlotChainIgnoresSlotsWithoutParent(t *testing.T) {
+	if _, _, ok := updateParent(slotUpdate(5)); ok {
+		t.Fatal("Slot update without parent should not link")
+	}
+
+	chain := newSlotChain(10)
+	chain.see(slotUpdate(100))
+	if chain.unknown(100) || chain.unknown(95) {
+		t.Fatal("Slots at or below the first slot should not be unknown")
+	}
+	chain.see(slotUpdate(120))
+	if chain.unknown(105) || !chain.unknown(115) {
+		t.Fatal("Only slots inside the window should be unknown")
+	}
+}
@@ -2,15 +2,16 @@ package yellowstone
 
 import (
 	"context"
-	"crypto/x509"
+	"crypto/tls"
 	"errors"
 	"net/url"
 	"time"
 
 	pb "github.com/andrew-solarstorm/yellowstone-grpc-client-go/proto"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
 	"google.golang.org/grpc/credentials"
-	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding"
 	"google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/keepalive"
 )
@@ -21,6 +22,7 @@ type GeyserGrpcBuilder struct {
 	xRequestSnapshot        bool
 	sendCompressed          bool
 	acceptCompressed        bool
+	compressionEncoding     string
 	maxDecodingMessageSize  int
 	maxEncodingMessageSize  int
 	connectTimeout          time.Duration
@@ -30,10 +32,21 @@ type GeyserGrpcBuilder struct {
 	tcpKeepalive            *time.Duration
 	tcpNodelay              bool
 	timeout                 time.Duration
-	http2AdaptiveWindow     bool
+	http2AdaptiveWindow     *bool
 	initialConnWindowSize   int
 	initialStreamWindowSize int
 	tlsConfig               *credentials.TransportCredentials
+	tlsClientConfig         *tls.Config
+	tlsCACertFile           string
+	tlsCACertPEM            []byte
+	tlsClientCertFile       string
+	tlsClientKeyFile        string
+	tlsClientCertPEM        []byte
+	tlsClientKeyPEM         []byte
+	tlsServerName           string
+	pingInterval            time.Duration
+	pingTimeout             time.Duration
+	connectProbe            ConnectProbe
 }
 
 func BuildFromShared(endpoint string) (*GeyserGrpcBuilder, error) {
@@ -72,8 +85,13 @@ func (b *GeyserGrpcBuilder) ConnectTimeout(dur time.Duration) *GeyserGrpcBuilder
 	return b
 }
 
+func (b *GeyserGrpcBuilder) ConnectProbe(probe ConnectProbe) *GeyserGrpcBuilder {
+	b.connectProbe = probe
+	return b
+}
+
 func (b *GeyserGrpcBuilder) HTTP2AdaptiveWindow(enabled bool) *GeyserGrpcBuilder {
-	b.http2AdaptiveWindow = enabled
+	b.http2AdaptiveWindow = &enabled
 	return b
 }
 
@@ -117,22 +135,74 @@ func (b *GeyserGrpcBuilder) Timeout(dur time.Duration) *GeyserGrpcBuilder {
 	return b
 }
 
-// func (b *GeyserGrpcBuilder) TLSConfig(config credentials.TransportCredentials) *GeyserGrpcBuilder {
-// 	b.tlsConfig = &config
-// 	b.useTLS = true
-// 	return b
-// }
+func (b *GeyserGrpcBuilder) SubscribePingInterval(interval time.Duration) *GeyserGrpcBuilder {
+	b.pingInterval = interval
+	return b
+}
+
+func (b *GeyserGrpcBuilder) SubscribePingTimeout(timeout time.Duration) *GeyserGrpcBuilder {
+	b.pingTimeout = timeout
+	return b
+}
+
+func (b *GeyserGrpcBuilder) TLSConfig(config credentials.TransportCredentials) *GeyserGrpcBuilder {
+	b.tlsConfig = &config
+	return b
+}
+
+func (b *GeyserGrpcBuilder) TLSClientConfig(config *tls.Config) *GeyserGrpcBuilder {
+	b.tlsClientConfig = config
+	return b
+}
+
+// TLSCACertFile and TLSCACert set the CA bundle that verifies the server. It
+// replaces the system roots unless TLSClientConfig supplies RootCAs, which
+// the bundle then extends.
+func (b *GeyserGrpcBuilder) TLSCACertFile(path string) *GeyserGrpcBuilder {
+	b.tlsCACertFile = path
+	return b
+}
+
+func (b *GeyserGrpcBuilder) TLSCACert(pem []byte) *GeyserGrpcBuilder {
+	b.tlsCACertPEM = pem
+	return b
+}
+
+func (b *GeyserGrpcBuilder) TLSClientCertFile(certPath, keyPath string) *GeyserGrpcBuilder {
+	b.tlsClientCertFile = certPath
+	b.tlsClientKeyFile = keyPath
+	return b
+}
+
+func (b *GeyserGrpcBuilder) TLSClientCert(certPEM, keyPEM []byte) *GeyserGrpcBuilder {
+	b.tlsClientCertPEM = certPEM
+	b.tlsClientKeyPEM = keyPEM
+	return b
+}
+
+func (b *GeyserGrpcBuilder) TLSServerName(name string) *GeyserGrpcBuilder {
+	b.tlsServerName = name
+	return b
+}
 
 func (b *GeyserGrpcBuilder) SendCompressed(enable bool) *GeyserGrpcBuilder {
 	b.sendCompressed = enable
 	return b
 }
 
+// AcceptCompressed documents intent only: gzip and zstd decompressors are
+// registered process-wide, so gRPC advertises and decodes them on every
+// connection regardless of this setting, and false has no effect.
 func (b *GeyserGrpcBuilder) AcceptCompressed(enable bool) *GeyserGrpcBuilder {
 	b.acceptCompressed = enable
 	return b
 }
 
+func (b *GeyserGrpcBuilder) CompressionEncoding(name string) *GeyserGrpcBuilder {
+	b.compressionEncoding = name
+	return b
+}
+
 func (b *GeyserGrpcBuilder) MaxDecodingMessageSize(limit int) *GeyserGrpcBuilder {
 	b.maxDecodingMessageSize = limit
 	return b
@@ -147,26 +217,48 @@ func (b *GeyserGrpcBuilder) build(conn *grpc.ClientConn) *GeyserGrpcClient {
 	geyser := pb.NewGeyserClient(conn)
 	health := grpc_health_v1.NewHealthClient(conn)
 
-	return NewGeyserGrpcClient(health, geyser, conn)
+	client := NewGeyserGrpcClient(health, geyser, conn)
+	client.pingInterval = b.pingInterval
+	client.pingTimeout = b.pingTimeout
+	return client
 }
 
 func (b *GeyserGrpcBuilder) Connect(ctx context.Context) (*GeyserGrpcClient, error) {
-	conn, err := b.dial(ctx)
+	if b.connectTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, b.connectTimeout)
+		defer cancel()
+	}
+
+	recorder := &connectRecorder{}
+	conn, err := b.dial(ctx, recorder)
 	if err != nil {
-		return nil, NewTransportError(err)
+		return nil, wrapDialError(err)
 	}
-	return b.build(conn), nil
+
+	if err := waitForReady(ctx, conn, recorder); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	client := b.build(conn)
+	if err := b.runConnectProbe(ctx, client); err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	return client, nil
 }
 
 func (b *GeyserGrpcBuilder) ConnectLazy() (*GeyserGrpcClient, error) {
-	conn, err := b.dial(context.Background())
+	conn, err := b.dial(context.Background(), nil)
 	if err != nil {
-		return nil, NewTransportError(err)
+		return nil, wrapDialError(err)
 	}
 	return b.build(conn), nil
 }
 
-func (b *GeyserGrpcBuilder) dial(ctx context.Context) (*grpc.ClientConn, error) {
+func (b *GeyserGrpcBuilder) dial(ctx context.Context, recorder *connectRecorder) (*grpc.ClientConn, error) {
 	u, err := url.Parse(b.endpoint)
 	if err != nil {
 		return nil, err
@@ -192,23 +284,46 @@ func (b *GeyserGrpcBuilder) dial(ctx context.Context) (*grpc.ClientConn, error)
 
 	var opts []grpc.DialOption
 
-	if b.tlsConfig != nil {
-		opts = append(opts, grpc.WithTransportCredentials(*b.tlsConfig))
-	} else if httpMode {
-		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
-	} else {
-		pool, _ := x509.SystemCertPool()
-		creds := credentials.NewClientTLSFromCert(pool, "")
-		opts = append(opts, grpc.WithTransportCredentials(creds))
+	creds, err := b.transportCredentials(httpMode)
+	if err != nil {
+		return nil, err
+	}
+	if recorder != nil {
+		creds = &recordingCredentials{TransportCredentials: creds, recorder: recorder}
 	}
+	opts = append(opts, grpc.WithTransportCredentials(creds))
 
 	interceptor := &InterceptorXToken{
 		XToken:           b.xToken,
 		XRequestSnapshot: b.xRequestSnapshot,
 	}
-	opts = append(opts, grpc.WithUnaryInterceptor(interceptor.UnaryInterceptor))
+	unaryInterceptors := []grpc.UnaryClientInterceptor{interceptor.UnaryInterceptor}
+	if b.timeout > 0 {
+		unaryInterceptors = append(unaryInterceptors, timeoutInterceptor(b.timeout))
+	}
+	opts = append(opts, grpc.WithChainUnaryInterceptor(unaryInterceptors...))
 	opts = append(opts, grpc.WithStreamInterceptor(interceptor.StreamInterceptor))
 
+	opts = append(opts, grpc.WithContextDialer(b.contextDialer(recorder)))
+
+	if b.connectTimeout > 0 {
+		opts = append(opts, grpc.WithConnectParams(grpc.ConnectParams{
+			Backoff:           backoff.DefaultConfig,
+			MinConnectTimeout: b.connectTimeout,
+		}))
+	}
+
+	if b.sendCompressed {
+		name := b.compressionEncoding
+		if name == "" {
+			name = CompressionGzip
+		}
+		if encoding.GetCompressor(name) == nil {
+			return nil, errors.New("unsupported compression encoding: " + name)
+		}
+		opts = append(opts, grpc.WithDefaultCallOptions(grpc.UseCompressor(name)))
+	}
+
 	if b.keepAliveInterval > 0 || b.keepAliveTimeout > 0 {
 		keepAliveParams := keepalive.ClientParameters{
 			PermitWithoutStream: b.keepAliveWithoutStream,
@@ -230,13 +345,7 @@ func (b *GeyserGrpcBuilder) dial(ctx context.Context) (*grpc.ClientConn, error)
 		opts = append(opts, grpc.WithDefaultCallOptions(grpc.MaxCallSendMsgSize(b.maxEncodingMessageSize)))
 	}
 
-	if b.initialConnWindowSize > 0 {
-		opts = append(opts, grpc.WithInitialConnWindowSize(int32(b.initialConnWindowSize)))
-	}
-
-	if b.initialStreamWindowSize > 0 {
-		opts = append(opts, grpc.WithInitialWindowSize(int32(b.initialStreamWindowSize)))
-	}
+	opts = append(opts, b.windowOptions()...)
 
 	conn, err := grpc.NewClient(address, opts...)
 	if err != nil {
@@ -245,3 +354,11 @@ func (b *GeyserGrpcBuilder) dial(ctx context.Context) (*grpc.ClientConn, error)
 
 	return conn, nil
 }
+
+func wrapDialError(err error) error {
+	var builderErr *GeyserGrpcBuilderError
+	if errors.As(err, &builderErr) {
+		return builderErr
+	}
+	return NewTransportError(err)
+}
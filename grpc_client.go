@@ -2,6 +2,8 @@ package yellowstone
 
 import (
 	"context"
+	"sync/atomic"
+	"time"
 
 	pb "github.com/andrew-solarstorm/yellowstone-grpc-client-go/proto"
 	"google.golang.org/grpc"
@@ -14,6 +16,10 @@ type GeyserGrpcClient struct {
 	conn   *grpc.ClientConn
 	ctx    context.Context
 	cancel context.CancelFunc
+
+	pingInterval time.Duration
+	pingTimeout  time.Duration
+	pingRTT      atomic.Int64
 }
 
 func NewGeyserGrpcClient(
@@ -33,21 +39,14 @@ func NewGeyserGrpcClient(
 
 func (c *GeyserGrpcClient) Start(stream pb.Geyser_SubscribeClient, fn func(*pb.SubscribeUpdate) error) error {
 	defer c.cancel()
-	for {
-		select {
-		case <-c.ctx.Done():
-			return nil
-		default:
-			msg, err := stream.Recv()
-			if err != nil {
-				return err
-			}
-
-			if err := fn(msg); err != nil {
-				return err
-			}
-		}
+	_, streamErr, fnErr := c.receive(stream, fn)
+	if fnErr != nil {
+		return fnErr
+	}
+	if c.ctx.Err() != nil {
+		return nil
 	}
+	return streamErr
 }
 
 func (c *GeyserGrpcClient) Close() error {
@@ -88,18 +87,39 @@ func (c *GeyserGrpcClient) SubscribeWithRequest(
 	ctx context.Context,
 	request *pb.SubscribeRequest,
 ) (pb.Geyser_SubscribeClient, error) {
-	stream, err := c.Geyser.Subscribe(ctx)
+	if err := ValidateSubscribeRequest(request); err != nil {
+		return nil, NewInvalidSubscribeRequestError(err)
+	}
+	return c.openSubscribe(ctx, request)
+}
+
+// openSubscribe opens a Subscribe stream and sends request, which the caller
+// has validated.
+func (c *GeyserGrpcClient) openSubscribe(
+	ctx context.Context,
+	request *pb.SubscribeRequest,
+) (pb.Geyser_SubscribeClient, error) {
+	streamCtx, cancel := context.WithCancelCause(ctx)
+	stream, err := c.Geyser.Subscribe(streamCtx)
 	if err != nil {
+		cancel(nil)
 		return nil, NewGrpcStatusError(err)
 	}
 
+	s := &subscribeStream{
+		Geyser_SubscribeClient: stream,
+		ctx:                    streamCtx,
+		cancel:                 cancel,
+	}
+
 	if request != nil {
-		if err := stream.Send(request); err != nil {
+		if err := s.Send(request); err != nil {
+			cancel(nil)
 			return nil, NewSubscribeSendError(err)
 		}
 	}
 
-	return stream, nil
+	return s, nil
 }
 
 func (c *GeyserGrpcClient) SubscribeOnce(
@@ -189,3 +209,12 @@ func (c *GeyserGrpcClient) GetVersion(ctx context.Context) (*pb.GetVersionRespon
 	}
 	return response, nil
 }
+
+func (c *GeyserGrpcClient) GetReplayInfo(ctx context.Context) (*pb.SubscribeReplayInfoResponse, error) {
+	request := &pb.SubscribeReplayInfoRequest{}
+	response, err := c.Geyser.SubscribeReplayInfo(ctx, request)
+	if err != nil {
+		return nil, NewGrpcStatusError(err)
+	}
+	return response, nil
+}
@@ -0,0 +1,118 @@
+package yellowstone
+
+import (
+	pb "github.com/andrew-solarstorm/yellowstone-grpc-client-go/proto"
+)
+
+// Handlers routes each update to the callback for its kind. Nil callbacks are
+// skipped. Handle matches the callback signature of Start, Subscription.Run
+// and the other consumption APIs.
+type Handlers struct {
+	OnAccount           func(update *pb.SubscribeUpdateAccount, filters []string)
+	OnSlot              func(update *pb.SubscribeUpdateSlot, filters []string)
+	OnTransaction       func(update *pb.SubscribeUpdateTransaction, filters []string)
+	OnTransactionStatus func(update *pb.SubscribeUpdateTransactionStatus, filters []string)
+	OnBlock             func(update *pb.SubscribeUpdateBlock, filters []string)
+	OnBlockMeta         func(update *pb.SubscribeUpdateBlockMeta, filters []string)
+	OnEntry             func(update *pb.SubscribeUpdateEntry, filters []string)
+	OnPing              func(update *pb.SubscribeUpdatePing)
+	OnPong              func(update *pb.SubscribeUpdatePong)
+}
+
+func (h *Handlers) Handle(update *pb.SubscribeUpdate) error {
+	filters := update.GetFilters()
+
+	switch u := update.GetUpdateOneof().(type) {
+	case *pb.SubscribeUpdate_Account:
+		if h.OnAccount != nil {
+			h.OnAccount(u.Account, filters)
+		}
+	case *pb.SubscribeUpdate_Slot:
+		if h.OnSlot != nil {
+			h.OnSlot(u.Slot, filters)
+		}
+	case *pb.SubscribeUpdate_Transaction:
+		if h.OnTransaction != nil {
+			h.OnTransaction(u.Transaction, filters)
+		}
+	case *pb.SubscribeUpdate_TransactionStatus:
+		if h.OnTransactionStatus != nil {
+			h.OnTransactionStatus(u.TransactionStatus, filters)
+		}
+	case *pb.SubscribeUpdate_Block:
+		if h.OnBlock != nil {
+			h.OnBlock(u.Block, filters)
+		}
+	case *pb.SubscribeUpdate_BlockMeta:
+		if h.OnBlockMeta != nil {
+			h.OnBlockMeta(u.BlockMeta, filters)
+		}
+	case *pb.SubscribeUpdate_Entry:
+		if h.OnEntry != nil {
+			h.OnEntry(u.Entry, filters)
+		}
+	case *pb.SubscribeUpdate_Ping:
+		if h.OnPing != nil {
+			h.OnPing(u.Ping)
+		}
+	case *pb.SubscribeUpdate_Pong:
+		if h.OnPong != nil {
+			h.OnPong(u.Pong)
+		}
+	}
+
+	return nil
+}
+
+// Router dispatches updates to Handlers registered per filter name, so one
+// subscription can feed several independent consumers. An update matching
+// several filters reaches each registered Handlers once. Updates matching no
+// route, including pings and pongs, go to the default Handlers.
+type Router struct {
+	routes   map[string][]*Handlers
+	fallback *Handlers
+}
+
+func NewRouter() *Router {
+	return &Router{routes: make(map[string][]*Handlers)}
+}
+
+func (r *Router) Route(filter string, handlers *Handlers) *Router {
+	r.routes[filter] = append(r.routes[filter], handlers)
+	return r
+}
+
+func (r *Router) Default(handlers *Handlers) *Router {
+	r.fallback = handlers
+	return r
+}
+
+func (r *Router) Handle(update *pb.SubscribeUpdate) error {
+	var called []*Handlers
+
+	for _, filter := range update.GetFilters() {
+		for _, handlers := range r.routes[filter] {
+			if containsHandlers(called, handlers) {
+				continue
+			}
+			called = append(called, handlers)
+			if err := handlers.Handle(update); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(called) == 0 && r.fallback != nil {
+		return r.fallback.Handle(update)
+	}
+	return nil
+}
+
+func containsHandlers(list []*Handlers, handlers *Handlers) bool {
+	for _, h := range list {
+		if h == handlers {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package yellowstone
+
+import (
+	"testing"
+
+	pb "github.com/andrew-solarstorm/yellowstone-grpc-client-go/proto"
+)
+
+func TestHandlersDispatch(t *testing.T) {
+	var (
+		accounts, slots, pings int
+		gotFilters             []string
+	)
+
+	h := &Handlers{
+		OnAccount: func(update *pb.SubscribeUpdateAccount, filters []string) {
+			accounts++
+			gotFilters = filters
+		},
+		OnSlot: func(update *pb.SubscribeUpdateSlot, filters []string) {
+			slots++
+		},
+		OnPing: func(*pb.SubscribeUpdatePing) {
+			pings++
+		},
+	}
+
+	updates := []*pb.SubscribeUpdate{
+		{Filters: []string{"wallets"}, UpdateOneof: &pb.SubscribeUpdate_Account{Account: &pb.SubscribeUpdateAccount{Slot: 1}}},
+		slotUpdate(2),
+		pingUpdate(),
+		{UpdateOneof: &pb.SubscribeUpdate_Entry{Entry: &pb.SubscribeUpdateEntry{}}},
+	}
+	for _, update := range updates {
+		if err := h.Handle(update); err != nil {
+			t.Fatalf("Handle failed: %v", err)
+		}
+	}
+
+	if accounts != 1 || slots != 1 || pings != 1 {
+		t.Errorf("Unexpected dispatch counts: accounts=%d slots=%d pings=%d", accounts, slots, pings)
+	}
+	if len(gotFilters) != 1 || gotFilters[0] != "wallets" {
+		t.Errorf("Expected filters to be passed through, got %v", gotFilters)
+	}
+}
+
+func TestRouterRoutesByFilter(t *testing.T) {
+	var walletTxs, dexTxs, fallback int
+
+	wallets := &Handlers{OnTransaction: func(*pb.SubscribeUpdateTransaction, []string) { walletTxs++ }}
+	dex := &Handlers{OnTransaction: func(*pb.SubscribeUpdateTransaction, []string) { dexTxs++ }}
+	router := NewRouter().
+		Route("wallets", wallets).
+		Route("dex", dex).
+		Route("dex_v2", dex).
+		Default(&Handlers{
+			OnTransaction: func(*pb.SubscribeUpdateTransaction, []string) { fallback++ },
+			OnPong:        func(*pb.SubscribeUpdatePong) { fallback++ },
+		})
+
+	tx := func(filters ...string) *pb.SubscribeUpdate {
+		return &pb.SubscribeUpdate{
+			Filters:     filters,
+			UpdateOneof: &pb.SubscribeUpdate_Transaction{Transaction: &pb.SubscribeUpdateTransaction{}},
+		}
+	}
+
+	for _, update := range []*pb.SubscribeUpdate{
+		tx("wallets"),
+		tx("dex", "dex_v2"),
+		tx("wallets", "dex"),
+		tx("other"),
+		pongUpdate(1),
+	} {
+		router.Handle(update)
+	}
+
+	if walletTxs != 2 {
+		t.Errorf("Expected 2 wallet transactions, got %d", walletTxs)
+	}
+	if dexTxs != 2 {
+		t.Errorf("Expected 2 dex transactions, got %d", dexTxs)
+	}
+	if fallback != 2 {
+		t.Errorf("Expected 2 fallback updates, got %d", fallback)
+	}
+}
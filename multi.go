@@ -0,0 +1,349 @@
+package yellowstone
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"sync"
+	"time"
+
+	pb "github.com/andrew-solarstorm/yellowstone-grpc-client-go/proto"
+)
+
+const defaultDedupeWindow = 150
+
+// MultiClient subscribes to several endpoints with the same request and merges
+// the streams, delivering the first copy of every update. Transactions are
+// keyed by (slot, signature), accounts by (pubkey, write_version) and slots by
+// (slot, status). Pings and pongs stay on their own connection.
+type MultiClient struct {
+	clients   []*GeyserGrpcClient
+	endpoints []string
+	policy    *ReconnectPolicy
+	window    uint64
+
+	mu      sync.Mutex
+	stats   []EndpointStats
+	seen    map[uint64]map[dedupeKey]arrival
+	highest uint64
+
+	// deliverMu serialises picking winners and calling fn, and guards
+	// failed. It is separate from mu so that fn may call Stats.
+	deliverMu sync.Mutex
+	failed    bool
+}
+
+type EndpointStats struct {
+	Endpoint string
+	// Received counts data updates; Wins those delivered first, Duplicates
+	// those another endpoint delivered earlier and Stale those older than the
+	// dedupe window.
+	Received   uint64
+	Wins       uint64
+	Duplicates uint64
+	Stale      uint64
+	// TotalLag and MaxLag measure how long after the winning copy this
+	// endpoint's duplicates arrived.
+	TotalLag time.Duration
+	MaxLag   time.Duration
+	Err      error
+}
+
+func (s EndpointStats) WinRate() float64 {
+	if s.Wins+s.Duplicates == 0 {
+		return 0
+	}
+	return float64(s.Wins) / float64(s.Wins+s.Duplicates)
+}
+
+func (s EndpointStats) AvgLag() time.Duration {
+	if s.Duplicates == 0 {
+		return 0
+	}
+	return s.TotalLag / time.Duration(s.Duplicates)
+}
+
+type dedupeKind uint8
+
+const (
+	dedupeAccount dedupeKind = iota + 1
+	dedupeSlot
+	dedupeTransaction
+	dedupeTransactionStatus
+	dedupeBlock
+	dedupeBlockMeta
+	dedupeEntry
+)
+
+type dedupeKey struct {
+	kind dedupeKind
+	id   string
+	n    uint64
+}
+
+type arrival struct {
+	endpoint int
+	at       time.Time
+}
+
+// NewMultiClient connects lazily to every builder. If one fails, the clients
+// opened so far are closed.
+func NewMultiClient(builders ...*GeyserGrpcBuilder) (*MultiClient, error) {
+	clients := make([]*GeyserGrpcClient, 0, len(builders))
+	endpoints := make([]string, 0, len(builders))
+	for _, builder := range builders {
+		client, err := builder.ConnectLazy()
+		if err != nil {
+			for _, c := range clients {
+				c.Close()
+			}
+			return nil, err
+		}
+		clients = append(clients, client)
+		endpoints = append(endpoints, builder.endpoint)
+	}
+	return newMultiClient(clients, endpoints), nil
+}
+
+func newMultiClient(clients []*GeyserGrpcClient, endpoints []string) *MultiClient {
+	m := &MultiClient{
+		clients:   clients,
+		endpoints: endpoints,
+		window:    defaultDedupeWindow,
+		stats:     make([]EndpointStats, len(clients)),
+	}
+	for i, endpoint := range endpoints {
+		m.stats[i].Endpoint = endpoint
+	}
+	return m
+}
+
+// SetReconnectPolicy makes each endpoint reconnect on retryable errors instead
+// of dropping out of the merge.
+func (m *MultiClient) SetReconnectPolicy(policy ReconnectPolicy) *MultiClient {
+	m.policy = &policy
+	return m
+}
+
+// SetDedupeWindow sets how many slots behind the highest seen slot keys are
+// remembered. Updates older than that are dropped and counted as stale.
+func (m *MultiClient) SetDedupeWindow(slots uint64) *MultiClient {
+	m.window = slots
+	return m
+}
+
+func (m *MultiClient) Clients() []*GeyserGrpcClient {
+	return m.clients
+}
+
+func (m *MultiClient) Stats() []EndpointStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stats := make([]EndpointStats, len(m.stats))
+	copy(stats, m.stats)
+	return stats
+}
+
+func (m *MultiClient) Close() error {
+	var errs []error
+	for _, client := range m.clients {
+		if err := client.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Run subscribes every endpoint and calls fn with the merged stream. Calls to
+// fn are serialised. Run returns when ctx is done, fn fails, or every endpoint
+// has ended; in the last case the endpoint errors are joined.
+func (m *MultiClient) Run(
+	ctx context.Context,
+	request *pb.SubscribeRequest,
+	fn func(*pb.SubscribeUpdate) error,
+) error {
+	if err := ValidateSubscribeRequest(request); err != nil {
+		return NewInvalidSubscribeRequestError(err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	m.mu.Lock()
+	m.seen = make(map[uint64]map[dedupeKey]arrival)
+	m.highest = 0
+	m.mu.Unlock()
+	m.deliverMu.Lock()
+	m.failed = false
+	m.deliverMu.Unlock()
+
+	var (
+		wg      sync.WaitGroup
+		fnErrMu sync.Mutex
+		fnErr   error
+		errs    = make([]error, len(m.clients))
+	)
+	for i := range m.clients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := m.runEndpoint(ctx, i, request, func(update *pb.SubscribeUpdate) error {
+				if err := m.deliver(i, update, fn); err != nil {
+					fnErrMu.Lock()
+					if fnErr == nil {
+						fnErr = err
+					}
+					fnErrMu.Unlock()
+					cancel()
+					return err
+				}
+				return nil
+			})
+			if err != nil {
+				errs[i] = err
+				m.mu.Lock()
+				m.stats[i].Err = err
+				m.mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if fnErr != nil {
+		return fnErr
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	return errors.Join(errs...)
+}
+
+func (m *MultiClient) Updates(
+	ctx context.Context,
+	request *pb.SubscribeRequest,
+	buffer int,
+) (<-chan *pb.SubscribeUpdate, <-chan error) {
+	return pumpUpdates(ctx, buffer, func(ctx context.Context, fn func(*pb.SubscribeUpdate) error) error {
+		return m.Run(ctx, request, fn)
+	})
+}
+
+func (m *MultiClient) UpdatesSeq(
+	ctx context.Context,
+	request *pb.SubscribeRequest,
+	buffer int,
+) iter.Seq2[*pb.SubscribeUpdate, error] {
+	return seqUpdates(ctx, func(ctx context.Context) (<-chan *pb.SubscribeUpdate, <-chan error) {
+		return m.Updates(ctx, request, buffer)
+	})
+}
+
+func (m *MultiClient) runEndpoint(
+	ctx context.Context,
+	i int,
+	request *pb.SubscribeRequest,
+	fn func(*pb.SubscribeUpdate) error,
+) error {
+	client := m.clients[i]
+	if m.policy != nil {
+		return client.SubscribeWithReconnect(request, *m.policy).Run(ctx, fn)
+	}
+	return client.runStream(ctx, request, fn)
+}
+
+func (m *MultiClient) deliver(i int, update *pb.SubscribeUpdate, fn func(*pb.SubscribeUpdate) error) error {
+	arrived := time.Now()
+	key, slot, ok := updateDedupeKey(update)
+	if !ok {
+		return nil
+	}
+
+	// Winners are picked and delivered under one lock so fn sees updates in
+	// the order they won.
+	m.deliverMu.Lock()
+	defer m.deliverMu.Unlock()
+	if !m.record(i, key, slot, arrived) {
+		return nil
+	}
+	if err := fn(update); err != nil {
+		m.failed = true
+		return err
+	}
+	return nil
+}
+
+// record counts an update received by endpoint i at arrived and reports
+// whether it is the first copy, which the caller then delivers. It is called
+// with deliverMu held.
+func (m *MultiClient) record(i int, key dedupeKey, slot uint64, arrived time.Time) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.failed {
+		return false
+	}
+
+	stats := &m.stats[i]
+	stats.Received++
+
+	if m.highest > m.window && slot < m.highest-m.window {
+		stats.Stale++
+		return false
+	}
+
+	keys := m.seen[slot]
+	if first, ok := keys[key]; ok {
+		lag := arrived.Sub(first.at)
+		stats.Duplicates++
+		stats.TotalLag += lag
+		if lag > stats.MaxLag {
+			stats.MaxLag = lag
+		}
+		return false
+	}
+
+	if keys == nil {
+		keys = make(map[dedupeKey]arrival)
+		m.seen[slot] = keys
+	}
+	keys[key] = arrival{endpoint: i, at: arrived}
+	stats.Wins++
+	if slot > m.highest {
+		m.highest = slot
+		m.prune()
+	}
+	return true
+}
+
+func (m *MultiClient) prune() {
+	if m.highest <= m.window {
+		return
+	}
+	horizon := m.highest - m.window
+	for slot := range m.seen {
+		if slot < horizon {
+			delete(m.seen, slot)
+		}
+	}
+}
+
+func updateDedupeKey(update *pb.SubscribeUpdate) (dedupeKey, uint64, bool) {
+	switch u := update.GetUpdateOneof().(type) {
+	case *pb.SubscribeUpdate_Account:
+		info := u.Account.GetAccount()
+		return dedupeKey{kind: dedupeAccount, id: string(info.GetPubkey()), n: info.GetWriteVersion()}, u.Account.GetSlot(), true
+	case *pb.SubscribeUpdate_Slot:
+		return dedupeKey{kind: dedupeSlot, n: uint64(u.Slot.GetStatus())}, u.Slot.GetSlot(), true
+	case *pb.SubscribeUpdate_Transaction:
+		return dedupeKey{kind: dedupeTransaction, id: string(u.Transaction.GetTransaction().GetSignature())}, u.Transaction.GetSlot(), true
+	case *pb.SubscribeUpdate_TransactionStatus:
+		return dedupeKey{kind: dedupeTransactionStatus, id: string(u.TransactionStatus.GetSignature())}, u.TransactionStatus.GetSlot(), true
+	case *pb.SubscribeUpdate_Block:
+		return dedupeKey{kind: dedupeBlock, id: u.Block.GetBlockhash()}, u.Block.GetSlot(), true
+	case *pb.SubscribeUpdate_BlockMeta:
+		return dedupeKey{kind: dedupeBlockMeta, id: u.BlockMeta.GetBlockhash()}, u.BlockMeta.GetSlot(), true
+	case *pb.SubscribeUpdate_Entry:
+		return dedupeKey{kind: dedupeEntry, n: u.Entry.GetIndex()}, u.Entry.GetSlot(), true
+	default:
+		return dedupeKey{}, 0, false
+	}
+}
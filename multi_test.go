@@ -0,0 +1,226 @@
+package yellowstone
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	pb "github.com/andrew-solarstorm/yellowstone-grpc-client-go/proto"
+)
+
+func slotRangeServer(from, to uint64) *testGeyserServer {
+	return &testGeyserServer{
+		subscribe: func(stream pbSubscribeServer) error {
+			if _, err := stream.Recv(); err != nil {
+				return err
+			}
+			for slot := from; slot <= to; slot++ {
+				if err := stream.Send(slotUpdate(slot)); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func TestMultiClientDeduplicates(t *testing.T) {
+	multi, err := NewMultiClient(
+		BuildFromStatic("http://"+startTestServer(t, slotRangeServer(1, 3))),
+		BuildFromStatic("http://"+startTestServer(t, slotRangeServer(2, 4))),
+	)
+	if err != nil {
+		t.Fatalf("NewMultiClient failed: %v", err)
+	}
+	defer multi.Close()
+
+	var slots []uint64
+	err = multi.Run(context.Background(), slotsRequest(), func(update *pb.SubscribeUpdate) error {
+		slots = append(slots, update.GetSlot().GetSlot())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	sort.Slice(slots, func(i, j int) bool { return slots[i] < slots[j] })
+	if len(slots) != 4 || slots[0] != 1 || slots[3] != 4 {
+		t.Fatalf("Expected slots 1..4 once each, got %v", slots)
+	}
+
+	var received, wins, duplicates uint64
+	for _, stats := range multi.Stats() {
+		received += stats.Received
+		wins += stats.Wins
+		duplicates += stats.Duplicates
+	}
+	if received != 6 || wins != 4 || duplicates != 2 {
+		t.Fatalf("Unexpected stats: received=%d wins=%d duplicates=%d", received, wins, duplicates)
+	}
+}
+
+func TestMultiClientCallbackError(t *testing.T) {
+	multi, err := NewMultiClient(
+		BuildFromStatic("http://"+startTestServer(t, sendSlotsServer(5, nil, true))),
+		BuildFromStatic("http://"+startTestServer(t, sendSlotsServer(5, nil, true))),
+	)
+	if err != nil {
+		t.Fatalf("NewMultiClient failed: %v", err)
+	}
+	defer multi.Close()
+
+	stop := errors.New("stop")
+	calls := 0
+	err = multi.Run(context.Background(), slotsRequest(), func(*pb.SubscribeUpdate) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) {
+		t.Fatalf("Expected callback error, got %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("Expected one callback, got %d", calls)
+	}
+}
+
+func TestMultiClientStatsFromCallback(t *testing.T) {
+	multi, err := NewMultiClient(
+		BuildFromStatic("http://"+startTestServer(t, slotRangeServer(1, 3))),
+		BuildFromStatic("http://"+startTestServer(t, slotRangeServer(1, 3))),
+	)
+	if err != nil {
+		t.Fatalf("NewMultiClient failed: %v", err)
+	}
+	defer multi.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var wins []uint64
+	err = multi.Run(ctx, slotsRequest(), func(*pb.SubscribeUpdate) error {
+		var total uint64
+		for _, stats := range multi.Stats() {
+			total += stats.Wins
+		}
+		wins = append(wins, total)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if ctx.Err() != nil {
+		t.Fatal("Stats called from the callback deadlocked")
+	}
+	if len(wins) != 3 || wins[0] == 0 || wins[2] != 3 {
+		t.Fatalf("Expected stats to count each delivered win, got %v", wins)
+	}
+}
+
+func TestMultiClientDeliversInWinningOrder(t *testing.T) {
+	multi := newMultiClient(make([]*GeyserGrpcClient, 2), []string{"a", "b"}).SetDedupeWindow(1000)
+	multi.seen = make(map[uint64]map[dedupeKey]arrival)
+
+	var (
+		mu        sync.Mutex
+		confirmed = make(map[uint64]bool)
+		inverted  []uint64
+	)
+	fn := func(update *pb.SubscribeUpdate) error {
+		slot := update.GetSlot()
+		mu.Lock()
+		defer mu.Unlock()
+		if slot.GetStatus() == pb.SlotStatus_SLOT_CONFIRMED {
+			confirmed[slot.GetSlot()] = true
+		} else if confirmed[slot.GetSlot()] {
+			inverted = append(inverted, slot.GetSlot())
+		}
+		runtime.Gosched()
+		return nil
+	}
+
+	// Both endpoints send every slot as processed, then confirmed, so a
+	// confirmed copy can only win after the processed one did.
+	var wg sync.WaitGroup
+	for i := range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for slot := uint64(1); slot <= 500; slot++ {
+				for _, status := range []pb.SlotStatus{pb.SlotStatus_SLOT_PROCESSED, pb.SlotStatus_SLOT_CONFIRMED} {
+					update := &pb.SubscribeUpdate{UpdateOneof: &pb.SubscribeUpdate_Slot{
+						Slot: &pb.SubscribeUpdateSlot{Slot: slot, Status: status},
+					}}
+					multi.deliver(i, update, fn)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(inverted) != 0 {
+		t.Fatalf("Confirmed delivered before processed for slots %v", inverted)
+	}
+	var wins uint64
+	for _, stats := range multi.Stats() {
+		wins += stats.Wins
+	}
+	if len(confirmed) != 500 || wins != 1000 {
+		t.Fatalf("Expected every status once, got %d confirmed and %d wins", len(confirmed), wins)
+	}
+}
+
+func TestMultiClientStaleUpdates(t *testing.T) {
+	multi := newMultiClient(make([]*GeyserGrpcClient, 1), []string{"a"}).SetDedupeWindow(10)
+	multi.seen = make(map[uint64]map[dedupeKey]arrival)
+
+	count := 0
+	fn := func(*pb.SubscribeUpdate) error {
+		count++
+		return nil
+	}
+	for _, slot := range []uint64{100, 80, 95} {
+		if err := multi.deliver(0, slotUpdate(slot), fn); err != nil {
+			t.Fatalf("deliver failed: %v", err)
+		}
+	}
+	if count != 2 {
+		t.Fatalf("Expected 2 deliveries, got %d", count)
+	}
+	if stats := multi.Stats()[0]; stats.Stale != 1 || stats.WinRate() != 1 {
+		t.Fatalf("Unexpected stats: %+v", stats)
+	}
+}
+
+func TestUpdateDedupeKey(t *testing.T) {
+	account := func(pubkey byte, writeVersion uint64) *pb.SubscribeUpdate {
+		return &pb.SubscribeUpdate{UpdateOneof: &pb.SubscribeUpdate_Account{Account: &pb.SubscribeUpdateAccount{
+			Slot:    7,
+			Account: &pb.SubscribeUpdateAccountInfo{Pubkey: []byte{pubkey}, WriteVersion: writeVersion},
+		}}}
+	}
+
+	a, _, _ := updateDedupeKey(account(1, 1))
+	b, _, _ := updateDedupeKey(account(1, 2))
+	c, _, _ := updateDedupeKey(account(1, 1))
+	if a == b || a != c {
+		t.Fatalf("Account keys should follow pubkey and write version")
+	}
+
+	confirmed := &pb.SubscribeUpdate{UpdateOneof: &pb.SubscribeUpdate_Slot{Slot: &pb.SubscribeUpdateSlot{
+		Slot:   7,
+		Status: pb.SlotStatus_SLOT_CONFIRMED,
+	}}}
+	p, _, _ := updateDedupeKey(slotUpdate(7))
+	q, _, _ := updateDedupeKey(confirmed)
+	if p == q {
+		t.Fatal("Slot keys should include the status")
+	}
+
+	if _, _, ok := updateDedupeKey(pingUpdate()); ok {
+		t.Fatal("Pings should not be deduplicated")
+	}
+}
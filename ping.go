@@ -0,0 +1,290 @@
+package yellowstone
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	pb "github.com/andrew-solarstorm/yellowstone-grpc-client-go/proto"
+)
+
+// serverPingReplyID is the id used when answering server pings. Client pings
+// start above it so their pongs can be told apart.
+const serverPingReplyID int32 = 1
+
+type subscribeStream struct {
+	pb.Geyser_SubscribeClient
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	sendMu sync.Mutex
+}
+
+func (s *subscribeStream) Send(request *pb.SubscribeRequest) error {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	return s.Geyser_SubscribeClient.Send(request)
+}
+
+func (s *subscribeStream) Recv() (*pb.SubscribeUpdate, error) {
+	msg, err := s.Geyser_SubscribeClient.Recv()
+	if err != nil {
+		err = streamCause(s.ctx, err)
+		s.cancel(nil)
+	}
+	return msg, err
+}
+
+func streamCause(ctx context.Context, err error) error {
+	var clientErr *GeyserGrpcClientError
+	if ctx.Err() != nil && errors.As(context.Cause(ctx), &clientErr) {
+		return clientErr
+	}
+	return err
+}
+
+type pinger struct {
+	interval time.Duration
+	timeout  time.Duration
+	send     func(id int32) error
+	abort    func(error)
+	onRTT    func(time.Duration)
+
+	mu      sync.Mutex
+	nextID  int32
+	waitID  int32
+	sentAt  time.Time
+	answers chan time.Duration
+}
+
+func newPinger(interval, timeout time.Duration, send func(int32) error, abort func(error), onRTT func(time.Duration)) *pinger {
+	if timeout <= 0 {
+		timeout = interval
+	}
+	return &pinger{
+		interval: interval,
+		timeout:  timeout,
+		send:     send,
+		abort:    abort,
+		onRTT:    onRTT,
+		nextID:   serverPingReplyID,
+		answers:  make(chan time.Duration, 1),
+	}
+}
+
+func (p *pinger) run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := p.send(p.expect()); err != nil {
+			return
+		}
+
+		timer := time.NewTimer(p.timeout)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case rtt := <-p.answers:
+			timer.Stop()
+			if p.onRTT != nil {
+				p.onRTT(rtt)
+			}
+		case <-timer.C:
+			p.abort(NewPingTimeoutError(p.timeout))
+			return
+		}
+	}
+}
+
+func (p *pinger) expect() int32 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.nextID++
+	if p.nextID <= serverPingReplyID {
+		p.nextID = serverPingReplyID + 1
+	}
+	p.waitID = p.nextID
+	p.sentAt = time.Now()
+	return p.waitID
+}
+
+func (p *pinger) pong(id int32) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.waitID == 0 || id != p.waitID {
+		return
+	}
+	p.waitID = 0
+
+	select {
+	case p.answers <- time.Since(p.sentAt):
+	default:
+	}
+}
+
+func (c *GeyserGrpcClient) receive(
+	stream pb.Geyser_SubscribeClient,
+	fn func(*pb.SubscribeUpdate) error,
+) (received bool, streamErr error, fnErr error) {
+	return receiveStream[*pb.SubscribeRequest, *pb.SubscribeUpdate](c, stream, func(id int32) *pb.SubscribeRequest {
+		return &pb.SubscribeRequest{Ping: &pb.SubscribeRequestPing{Id: id}}
+	}, fn)
+}
+
+// pingStream is the part of a Subscribe or SubscribeDeshred stream the
+// receive loop needs.
+type pingStream[Req, Upd any] interface {
+	Send(Req) error
+	Recv() (Upd, error)
+	Context() context.Context
+	CloseSend() error
+}
+
+type keepaliveUpdate interface {
+	GetPing() *pb.SubscribeUpdatePing
+	GetPong() *pb.SubscribeUpdatePong
+}
+
+// abortableStream is implemented by the streams this package opens, whose
+// context it can cancel with a cause.
+type abortableStream interface {
+	abort(error)
+}
+
+func (s *subscribeStream) abort(err error) {
+	s.cancel(err)
+}
+
+// receiveStream feeds fn until the stream or fn fails. It answers server
+// pings with serverPingReplyID and, when client pings are configured, sends
+// them with ping and aborts the stream if a pong is late. Streams opened by
+// this package are aborted through their context; any other stream is read
+// from a separate goroutine so the loop can return the PingTimeout error
+// while that goroutine waits for the stream to end after CloseSend.
+func receiveStream[Req any, Upd keepaliveUpdate](
+	c *GeyserGrpcClient,
+	stream pingStream[Req, Upd],
+	ping func(id int32) Req,
+	fn func(Upd) error,
+) (received bool, streamErr error, fnErr error) {
+	recv := stream.Recv
+	abort := func(err error) {
+		if s, ok := stream.(abortableStream); ok {
+			s.abort(err)
+		}
+	}
+	if _, ok := stream.(abortableStream); !ok && c.pingInterval > 0 {
+		aborted := make(chan error, 1)
+		abort = func(err error) {
+			select {
+			case aborted <- err:
+			default:
+			}
+			stream.CloseSend()
+		}
+		var stopRecv func()
+		recv, stopRecv = abortableRecv(stream.Recv, aborted)
+		defer stopRecv()
+	}
+
+	p, stop := c.startPinger(
+		stream.Context(),
+		func(id int32) error {
+			return stream.Send(ping(id))
+		},
+		abort,
+	)
+	defer stop()
+
+	for {
+		if c.ctx.Err() != nil {
+			return received, c.ctx.Err(), nil
+		}
+
+		msg, err := recv()
+		if err != nil {
+			return received, err, nil
+		}
+		received = true
+
+		if msg.GetPing() != nil {
+			if err := stream.Send(ping(serverPingReplyID)); err != nil {
+				return received, NewSubscribeSendError(err), nil
+			}
+		} else if pong := msg.GetPong(); pong != nil && p != nil {
+			p.pong(pong.GetId())
+		}
+
+		if err := fn(msg); err != nil {
+			return received, nil, err
+		}
+	}
+}
+
+// abortableRecv reads from recv on its own goroutine and returns a receive
+// function that also yields the first error sent on aborted.
+func abortableRecv[Upd any](recv func() (Upd, error), aborted <-chan error) (func() (Upd, error), func()) {
+	type result struct {
+		msg Upd
+		err error
+	}
+	results := make(chan result)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			msg, err := recv()
+			select {
+			case results <- result{msg, err}:
+			case <-done:
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	return func() (Upd, error) {
+		select {
+		case r := <-results:
+			return r.msg, r.err
+		case err := <-aborted:
+			var zero Upd
+			return zero, err
+		}
+	}, func() { close(done) }
+}
+
+func (c *GeyserGrpcClient) startPinger(
+	ctx context.Context,
+	send func(id int32) error,
+	abort func(error),
+) (*pinger, context.CancelFunc) {
+	if c.pingInterval <= 0 {
+		return nil, func() {}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	p := newPinger(c.pingInterval, c.pingTimeout, send, abort, c.storePingRTT)
+	go p.run(ctx)
+	return p, cancel
+}
+
+func (c *GeyserGrpcClient) storePingRTT(rtt time.Duration) {
+	c.pingRTT.Store(int64(rtt))
+}
+
+func (c *GeyserGrpcClient) PingRTT() time.Duration {
+	return time.Duration(c.pingRTT.Load())
+}
@@ -0,0 +1,201 @@
+package yellowstone
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	pb "github.com/andrew-solarstorm/yellowstone-grpc-client-go/proto"
+	"google.golang.org/grpc"
+)
+
+func pingUpdate() *pb.SubscribeUpdate {
+	return &pb.SubscribeUpdate{UpdateOneof: &pb.SubscribeUpdate_Ping{Ping: &pb.SubscribeUpdatePing{}}}
+}
+
+func pongUpdate(id int32) *pb.SubscribeUpdate {
+	return &pb.SubscribeUpdate{UpdateOneof: &pb.SubscribeUpdate_Pong{Pong: &pb.SubscribeUpdatePong{Id: id}}}
+}
+
+func TestStartAnswersServerPing(t *testing.T) {
+	replies := make(chan *pb.SubscribeRequest, 1)
+	srv := &testGeyserServer{
+		subscribe: func(stream grpc.BidiStreamingServer[pb.SubscribeRequest, pb.SubscribeUpdate]) error {
+			if _, err := stream.Recv(); err != nil {
+				return err
+			}
+			if err := stream.Send(pingUpdate()); err != nil {
+				return err
+			}
+			req, err := stream.Recv()
+			if err != nil {
+				return err
+			}
+			replies <- req
+			return nil
+		},
+	}
+
+	client := connectTestClient(t, startTestServer(t, srv))
+	stream, err := client.SubscribeWithRequest(context.Background(), slotsRequest())
+	if err != nil {
+		t.Fatalf("SubscribeWithRequest failed: %v", err)
+	}
+
+	pings := 0
+	client.Start(stream, func(update *pb.SubscribeUpdate) error {
+		if update.GetPing() != nil {
+			pings++
+		}
+		return nil
+	})
+
+	select {
+	case req := <-replies:
+		if req.GetPing() == nil {
+			t.Fatalf("Expected ping reply, got %v", req)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for ping reply")
+	}
+
+	if pings != 1 {
+		t.Errorf("Expected ping to be forwarded to callback once, got %d", pings)
+	}
+}
+
+func TestClientPingMeasuresRTT(t *testing.T) {
+	srv := &testGeyserServer{
+		subscribe: func(stream grpc.BidiStreamingServer[pb.SubscribeRequest, pb.SubscribeUpdate]) error {
+			for {
+				req, err := stream.Recv()
+				if err != nil {
+					return nil
+				}
+				if ping := req.GetPing(); ping != nil {
+					if err := stream.Send(pongUpdate(ping.GetId())); err != nil {
+						return err
+					}
+				}
+			}
+		},
+	}
+
+	addr := startTestServer(t, srv)
+	client, err := BuildFromStatic("http://" + addr).
+		SubscribePingInterval(10 * time.Millisecond).
+		SubscribePingTimeout(time.Second).
+		ConnectLazy()
+	if err != nil {
+		t.Fatalf("ConnectLazy failed: %v", err)
+	}
+	defer client.Close()
+
+	stream, err := client.SubscribeWithRequest(context.Background(), slotsRequest())
+	if err != nil {
+		t.Fatalf("SubscribeWithRequest failed: %v", err)
+	}
+
+	pongs := 0
+	client.Start(stream, func(update *pb.SubscribeUpdate) error {
+		if update.GetPong() != nil {
+			pongs++
+		}
+		if pongs == 3 {
+			return errors.New("done")
+		}
+		return nil
+	})
+
+	if client.PingRTT() <= 0 {
+		t.Errorf("Expected positive ping RTT, got %v", client.PingRTT())
+	}
+}
+
+func TestClientPingTimeout(t *testing.T) {
+	srv := &testGeyserServer{
+		subscribe: func(stream grpc.BidiStreamingServer[pb.SubscribeRequest, pb.SubscribeUpdate]) error {
+			for {
+				if _, err := stream.Recv(); err != nil {
+					return nil
+				}
+			}
+		},
+	}
+
+	addr := startTestServer(t, srv)
+	client, err := BuildFromStatic("http://" + addr).
+		SubscribePingInterval(10 * time.Millisecond).
+		SubscribePingTimeout(50 * time.Millisecond).
+		ConnectLazy()
+	if err != nil {
+		t.Fatalf("ConnectLazy failed: %v", err)
+	}
+	defer client.Close()
+
+	stream, err := client.SubscribeWithRequest(context.Background(), slotsRequest())
+	if err != nil {
+		t.Fatalf("SubscribeWithRequest failed: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- client.Start(stream, func(*pb.SubscribeUpdate) error { return nil })
+	}()
+
+	select {
+	case err := <-done:
+		var clientErr *GeyserGrpcClientError
+		if !errors.As(err, &clientErr) || clientErr.Type != "PingTimeout" {
+			t.Fatalf("Expected PingTimeout error, got %v", err)
+		}
+		if !IsRetryableError(err) {
+			t.Error("Expected ping timeout to be retryable")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Stream was not aborted after missing pong")
+	}
+}
+
+func TestClientPingTimeoutForeignStream(t *testing.T) {
+	srv := &testGeyserServer{
+		subscribe: func(stream grpc.BidiStreamingServer[pb.SubscribeRequest, pb.SubscribeUpdate]) error {
+			for {
+				if _, err := stream.Recv(); err != nil {
+					return nil
+				}
+			}
+		},
+	}
+
+	addr := startTestServer(t, srv)
+	client, err := BuildFromStatic("http://" + addr).
+		SubscribePingInterval(10 * time.Millisecond).
+		SubscribePingTimeout(50 * time.Millisecond).
+		ConnectLazy()
+	if err != nil {
+		t.Fatalf("ConnectLazy failed: %v", err)
+	}
+	defer client.Close()
+
+	stream, err := client.Geyser.Subscribe(context.Background())
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- client.Start(stream, func(*pb.SubscribeUpdate) error { return nil })
+	}()
+
+	select {
+	case err := <-done:
+		var clientErr *GeyserGrpcClientError
+		if !errors.As(err, &clientErr) || clientErr.Type != "PingTimeout" {
+			t.Fatalf("Expected PingTimeout error, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Foreign stream was not aborted after missing pong")
+	}
+}
@@ -0,0 +1,248 @@
+package yellowstone
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	pb "github.com/andrew-solarstorm/yellowstone-grpc-client-go/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestIsRetryableError(t *testing.T) {
+	retryable := []codes.Code{codes.Unavailable, codes.Internal, codes.DeadlineExceeded, codes.ResourceExhausted}
+	for _, code := range retryable {
+		if !IsRetryableError(NewGrpcStatusError(status.Error(code, "x"))) {
+			t.Errorf("Expected %s to be retryable", code)
+		}
+	}
+
+	fatal := []codes.Code{codes.Unauthenticated, codes.PermissionDenied, codes.InvalidArgument, codes.Unimplemented}
+	for _, code := range fatal {
+		if IsRetryableError(status.Error(code, "x")) {
+			t.Errorf("Expected %s to be fatal", code)
+		}
+	}
+
+	if IsRetryableError(errors.New("plain")) {
+		t.Error("Expected non-status error to be fatal")
+	}
+}
+
+func TestReconnectPolicyBackoff(t *testing.T) {
+	policy := ReconnectPolicy{
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		Multiplier:     2,
+	}
+
+	expected := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second}
+	for i, want := range expected {
+		if got := policy.Backoff(i + 1); got != want {
+			t.Errorf("Attempt %d: expected %v, got %v", i+1, want, got)
+		}
+	}
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		got := policy.Backoff(5)
+		if got < 500*time.Millisecond || got > time.Second {
+			t.Fatalf("Jittered backoff out of range: %v", got)
+		}
+	}
+}
+
+func TestSubscriptionResumesFromLastProcessedSlot(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []*pb.SubscribeRequest
+	)
+
+	srv := &testGeyserServer{
+		subscribe: func(stream grpc.BidiStreamingServer[pb.SubscribeRequest, pb.SubscribeUpdate]) error {
+			req, err := stream.Recv()
+			if err != nil {
+				return err
+			}
+
+			mu.Lock()
+			requests = append(requests, req)
+			n := len(requests)
+			mu.Unlock()
+
+			if n == 1 {
+				for slot := uint64(10); slot <= 12; slot++ {
+					if err := stream.Send(slotUpdate(slot)); err != nil {
+						return err
+					}
+				}
+				return status.Error(codes.Unavailable, "restarting")
+			}
+
+			if err := stream.Send(slotUpdate(20)); err != nil {
+				return err
+			}
+			<-stream.Context().Done()
+			return nil
+		},
+	}
+
+	client := connectTestClient(t, startTestServer(t, srv))
+
+	var events []ReconnectEvent
+	policy := ReconnectPolicy{
+		InitialBackoff: time.Millisecond,
+		OnReconnect: func(event ReconnectEvent) {
+			events = append(events, event)
+		},
+	}
+
+	sub := client.SubscribeWithReconnect(&pb.SubscribeRequest{
+		Slots: map[string]*pb.SubscribeRequestFilterSlots{"slot": {}},
+	}, policy)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := sub.Run(ctx, func(update *pb.SubscribeUpdate) error {
+		if update.GetSlot().GetSlot() == 20 {
+			cancel()
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(requests) != 2 {
+		t.Fatalf("Expected 2 subscribe requests, got %d", len(requests))
+	}
+	if requests[0].FromSlot != nil {
+		t.Errorf("Expected no FromSlot on first request, got %d", requests[0].GetFromSlot())
+	}
+	if requests[1].GetFromSlot() != 11 {
+		t.Errorf("Expected FromSlot 11 on resume, got %d", requests[1].GetFromSlot())
+	}
+	if _, ok := requests[1].Slots["slot"]; !ok {
+		t.Error("Expected filters to be re-sent on resume")
+	}
+
+	if len(events) != 1 || events[0].Attempt != 1 || events[0].FromSlot == nil || *events[0].FromSlot != 11 {
+		t.Errorf("Unexpected reconnect events: %+v", events)
+	}
+}
+
+func TestSubscriptionResumesBlocksWithoutReplay(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []*pb.SubscribeRequest
+	)
+
+	srv := &testGeyserServer{
+		subscribe: func(stream pbSubscribeServer) error {
+			req, err := stream.Recv()
+			if err != nil {
+				return err
+			}
+
+			mu.Lock()
+			requests = append(requests, req)
+			n := len(requests)
+			mu.Unlock()
+
+			update := &pb.SubscribeUpdate{UpdateOneof: &pb.SubscribeUpdate_Block{
+				Block: &pb.SubscribeUpdateBlock{Slot: uint64(10 * n)},
+			}}
+			if err := stream.Send(update); err != nil {
+				return err
+			}
+			if n == 1 {
+				return status.Error(codes.Unavailable, "restarting")
+			}
+			<-stream.Context().Done()
+			return nil
+		},
+	}
+
+	client := connectTestClient(t, startTestServer(t, srv))
+
+	var events []ReconnectEvent
+	sub := client.SubscribeWithReconnect(&pb.SubscribeRequest{
+		Blocks: map[string]*pb.SubscribeRequestFilterBlocks{"blocks": {}},
+	}, ReconnectPolicy{
+		InitialBackoff: time.Millisecond,
+		OnReconnect:    func(event ReconnectEvent) { events = append(events, event) },
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := sub.Run(ctx, func(update *pb.SubscribeUpdate) error {
+		if update.GetBlock().GetSlot() == 20 {
+			cancel()
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(requests) != 2 {
+		t.Fatalf("Expected 2 subscribe requests, got %d", len(requests))
+	}
+	if requests[1].FromSlot != nil || len(requests[1].Blocks) != 1 {
+		t.Errorf("Expected blocks to be resubscribed without FromSlot, got %v", requests[1])
+	}
+	if len(events) != 1 || events[0].FromSlot != nil {
+		t.Errorf("Unexpected reconnect events: %+v", events)
+	}
+}
+
+func TestSubscriptionFatalError(t *testing.T) {
+	calls := 0
+	srv := &testGeyserServer{
+		subscribe: func(stream grpc.BidiStreamingServer[pb.SubscribeRequest, pb.SubscribeUpdate]) error {
+			calls++
+			return status.Error(codes.Unauthenticated, "bad token")
+		},
+	}
+
+	client := connectTestClient(t, startTestServer(t, srv))
+	sub := client.SubscribeWithReconnect(nil, ReconnectPolicy{InitialBackoff: time.Millisecond})
+
+	err := sub.Run(context.Background(), func(*pb.SubscribeUpdate) error { return nil })
+	if status.Code(errors.Unwrap(err)) != codes.Unauthenticated {
+		t.Fatalf("Expected Unauthenticated error, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected a single attempt, got %d", calls)
+	}
+}
+
+func TestSubscriptionMaxAttempts(t *testing.T) {
+	srv := &testGeyserServer{
+		subscribe: func(stream grpc.BidiStreamingServer[pb.SubscribeRequest, pb.SubscribeUpdate]) error {
+			return status.Error(codes.Unavailable, "down")
+		},
+	}
+
+	client := connectTestClient(t, startTestServer(t, srv))
+	sub := client.SubscribeWithReconnect(nil, ReconnectPolicy{InitialBackoff: time.Millisecond, MaxAttempts: 2})
+
+	err := sub.Run(context.Background(), func(*pb.SubscribeUpdate) error { return nil })
+
+	var clientErr *GeyserGrpcClientError
+	if !errors.As(err, &clientErr) || clientErr.Type != "ReconnectExhausted" {
+		t.Fatalf("Expected ReconnectExhausted error, got %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package yellowstone
+
+import (
+	"context"
+
+	pb "github.com/andrew-solarstorm/yellowstone-grpc-client-go/proto"
+)
+
+// ResumePoint is the outcome of fitting a requested resume slot into the
+// server's replay window.
+type ResumePoint struct {
+	Requested uint64
+	// FromSlot is the slot to send as SubscribeRequest.FromSlot. It is nil
+	// when the server keeps no replay history.
+	FromSlot *uint64
+	// Gap reports that slots from Requested up to FromSlot cannot be replayed
+	// and must be fetched from another source.
+	Gap bool
+	// Missing is the number of unrecoverable slots, zero when the server keeps
+	// no replay history and the size of the gap is unknown.
+	Missing uint64
+}
+
+func ClampFromSlot(requested uint64, info *pb.SubscribeReplayInfoResponse) ResumePoint {
+	point := ResumePoint{Requested: requested}
+
+	if info == nil || info.FirstAvailable == nil {
+		point.Gap = true
+		return point
+	}
+
+	fromSlot := requested
+	if first := info.GetFirstAvailable(); fromSlot < first {
+		point.Gap = true
+		point.Missing = first - fromSlot
+		fromSlot = first
+	}
+	point.FromSlot = &fromSlot
+	return point
+}
+
+// replayable reports whether request can be resumed with FromSlot, which the
+// server does not support for block and entry filters.
+func replayable(request *pb.SubscribeRequest) bool {
+	return len(request.GetBlocks()) == 0 && len(request.GetEntry()) == 0
+}
+
+func (p ResumePoint) Apply(request *pb.SubscribeRequest) {
+	if p.FromSlot == nil {
+		request.FromSlot = nil
+		return
+	}
+	fromSlot := *p.FromSlot
+	request.FromSlot = &fromSlot
+}
+
+func (c *GeyserGrpcClient) ResumeFrom(ctx context.Context, requested uint64) (ResumePoint, error) {
+	info, err := c.GetReplayInfo(ctx)
+	if err != nil {
+		return ResumePoint{}, err
+	}
+	return ClampFromSlot(requested, info), nil
+}
@@ -0,0 +1,76 @@
+package yellowstone
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/andrew-solarstorm/yellowstone-grpc-client-go/proto"
+)
+
+func TestClampFromSlot(t *testing.T) {
+	first := uint64(1000)
+	info := &pb.SubscribeReplayInfoResponse{FirstAvailable: &first}
+
+	tests := []struct {
+		name      string
+		requested uint64
+		info      *pb.SubscribeReplayInfoResponse
+		fromSlot  uint64
+		replay    bool
+		gap       bool
+		missing   uint64
+	}{
+		{name: "inside window", requested: 1500, info: info, fromSlot: 1500, replay: true},
+		{name: "first available", requested: 1000, info: info, fromSlot: 1000, replay: true},
+		{name: "before window", requested: 900, info: info, fromSlot: 1000, replay: true, gap: true, missing: 100},
+		{name: "no replay", requested: 900, info: &pb.SubscribeReplayInfoResponse{}, gap: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			point := ClampFromSlot(tt.requested, tt.info)
+
+			if (point.FromSlot != nil) != tt.replay {
+				t.Fatalf("Expected FromSlot set=%v, got %v", tt.replay, point.FromSlot)
+			}
+			if tt.replay && *point.FromSlot != tt.fromSlot {
+				t.Errorf("Expected FromSlot %d, got %d", tt.fromSlot, *point.FromSlot)
+			}
+			if point.Gap != tt.gap || point.Missing != tt.missing {
+				t.Errorf("Expected gap=%v missing=%d, got gap=%v missing=%d", tt.gap, tt.missing, point.Gap, point.Missing)
+			}
+
+			request := slotsRequest()
+			point.Apply(request)
+			if (request.FromSlot != nil) != tt.replay {
+				t.Errorf("Expected request FromSlot set=%v, got %v", tt.replay, request.FromSlot)
+			}
+		})
+	}
+}
+
+func TestResumeFrom(t *testing.T) {
+	srv := &testGeyserServer{
+		replayInfo: func(context.Context) (*pb.SubscribeReplayInfoResponse, error) {
+			first := uint64(500)
+			return &pb.SubscribeReplayInfoResponse{FirstAvailable: &first}, nil
+		},
+	}
+	client := connectTestClient(t, startTestServer(t, srv))
+
+	info, err := client.GetReplayInfo(context.Background())
+	if err != nil {
+		t.Fatalf("GetReplayInfo failed: %v", err)
+	}
+	if info.GetFirstAvailable() != 500 {
+		t.Errorf("Expected first available 500, got %d", info.GetFirstAvailable())
+	}
+
+	point, err := client.ResumeFrom(context.Background(), 400)
+	if err != nil {
+		t.Fatalf("ResumeFrom failed: %v", err)
+	}
+	if !point.Gap || point.Missing != 100 || point.FromSlot == nil || *point.FromSlot != 500 {
+		t.Errorf("Unexpected resume point: %+v", point)
+	}
+}
@@ -0,0 +1,91 @@
+package yellowstone
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	pb "github.com/andrew-solarstorm/yellowstone-grpc-client-go/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+type pbSubscribeServer = grpc.BidiStreamingServer[pb.SubscribeRequest, pb.SubscribeUpdate]
+
+type testGeyserServer struct {
+	pb.UnimplementedGeyserServer
+	subscribe  func(grpc.BidiStreamingServer[pb.SubscribeRequest, pb.SubscribeUpdate]) error
+	getVersion func(context.Context) (*pb.GetVersionResponse, error)
+	replayInfo func(context.Context) (*pb.SubscribeReplayInfoResponse, error)
+
+	subscribeDeshred func(grpc.BidiStreamingServer[pb.SubscribeDeshredRequest, pb.SubscribeUpdateDeshred]) error
+}
+
+func (s *testGeyserServer) Subscribe(stream grpc.BidiStreamingServer[pb.SubscribeRequest, pb.SubscribeUpdate]) error {
+	return s.subscribe(stream)
+}
+
+func (s *testGeyserServer) SubscribeDeshred(stream grpc.BidiStreamingServer[pb.SubscribeDeshredRequest, pb.SubscribeUpdateDeshred]) error {
+	return s.subscribeDeshred(stream)
+}
+
+func (s *testGeyserServer) GetVersion(ctx context.Context, _ *pb.GetVersionRequest) (*pb.GetVersionResponse, error) {
+	if s.getVersion != nil {
+		return s.getVersion(ctx)
+	}
+	return &pb.GetVersionResponse{Version: "test"}, nil
+}
+
+func (s *testGeyserServer) SubscribeReplayInfo(ctx context.Context, _ *pb.SubscribeReplayInfoRequest) (*pb.SubscribeReplayInfoResponse, error) {
+	if s.replayInfo != nil {
+		return s.replayInfo(ctx)
+	}
+	return &pb.SubscribeReplayInfoResponse{}, nil
+}
+
+func startTestServer(t *testing.T, srv *testGeyserServer, opts ...grpc.ServerOption) string {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+
+	server := grpc.NewServer(opts...)
+	pb.RegisterGeyserServer(server, srv)
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("geyser.Geyser", grpc_health_v1.HealthCheckResponse_SERVING)
+	grpc_health_v1.RegisterHealthServer(server, healthServer)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	return lis.Addr().String()
+}
+
+func connectTestClient(t *testing.T, addr string) *GeyserGrpcClient {
+	t.Helper()
+
+	client, err := BuildFromStatic("http://" + addr).ConnectLazy()
+	if err != nil {
+		t.Fatalf("ConnectLazy failed: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	return client
+}
+
+func slotsRequest() *pb.SubscribeRequest {
+	return &pb.SubscribeRequest{
+		Slots: map[string]*pb.SubscribeRequestFilterSlots{"slot": {}},
+	}
+}
+
+func slotUpdate(slot uint64) *pb.SubscribeUpdate {
+	return &pb.SubscribeUpdate{
+		UpdateOneof: &pb.SubscribeUpdate_Slot{
+			Slot: &pb.SubscribeUpdateSlot{Slot: slot},
+		},
+	}
+}
@@ -0,0 +1,176 @@
+package yellowstone
+
+import (
+	"context"
+	"sync"
+
+	pb "github.com/andrew-solarstorm/yellowstone-grpc-client-go/proto"
+	"google.golang.org/protobuf/proto"
+)
+
+// Subscription is a Subscribe stream that survives transport failures. After a
+// retryable error it re-dials, re-sends the current request and resumes from
+// the last fully processed slot, so updates of that slot may be delivered
+// twice. Filter changes made through the handle are sent on the open stream
+// and kept, so a reconnect restores them.
+type Subscription struct {
+	client *GeyserGrpcClient
+	policy ReconnectPolicy
+
+	// updateMu orders filter changes with each other and with stream setup.
+	updateMu sync.Mutex
+
+	mu          sync.Mutex
+	request     *pb.SubscribeRequest
+	stream      pb.Geyser_SubscribeClient
+	highestSlot uint64
+}
+
+func (c *GeyserGrpcClient) SubscribeWithReconnect(
+	request *pb.SubscribeRequest,
+	policy ReconnectPolicy,
+) *Subscription {
+	if request == nil {
+		request = &pb.SubscribeRequest{}
+	}
+	return &Subscription{
+		client:  c,
+		policy:  policy,
+		request: proto.Clone(request).(*pb.SubscribeRequest),
+	}
+}
+
+func (s *Subscription) Request() *pb.SubscribeRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return proto.Clone(s.request).(*pb.SubscribeRequest)
+}
+
+func (s *Subscription) LastProcessedSlot() (uint64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.highestSlot == 0 {
+		return 0, false
+	}
+	return s.highestSlot - 1, true
+}
+
+func (s *Subscription) Run(ctx context.Context, fn func(*pb.SubscribeUpdate) error) error {
+	attempt := 0
+	for {
+		received, streamErr, fnErr := s.runOnce(ctx, fn)
+		if fnErr != nil {
+			return fnErr
+		}
+		if ctx.Err() != nil || s.client.ctx.Err() != nil {
+			return nil
+		}
+		if !IsRetryableError(streamErr) {
+			return wrapStreamError(streamErr)
+		}
+
+		if received {
+			attempt = 0
+		}
+		attempt++
+		if s.policy.MaxAttempts > 0 && attempt > s.policy.MaxAttempts {
+			return NewReconnectExhaustedError(streamErr)
+		}
+
+		delay := s.policy.Backoff(attempt)
+		if s.policy.OnReconnect != nil {
+			event := ReconnectEvent{
+				Attempt: attempt,
+				Err:     streamErr,
+				Delay:   delay,
+			}
+			if slot, ok := s.LastProcessedSlot(); ok && replayable(s.Request()) {
+				event.FromSlot = &slot
+			}
+			s.policy.OnReconnect(event)
+		}
+
+		if err := sleepContext(ctx, delay); err != nil {
+			return nil
+		}
+
+		if s.client.conn != nil {
+			s.client.conn.Connect()
+		}
+	}
+}
+
+func (s *Subscription) runOnce(
+	ctx context.Context,
+	fn func(*pb.SubscribeUpdate) error,
+) (received bool, streamErr error, fnErr error) {
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stop := context.AfterFunc(s.client.ctx, cancel)
+	defer stop()
+
+	s.updateMu.Lock()
+	// A subscription may have dropped all of its filters, so only the
+	// filters themselves are validated.
+	request := s.resumeRequest()
+	if err := validateSubscribeFilters(request); err != nil {
+		s.updateMu.Unlock()
+		return false, NewInvalidSubscribeRequestError(err), nil
+	}
+	stream, err := s.client.openSubscribe(streamCtx, request)
+	if err != nil {
+		s.updateMu.Unlock()
+		return false, err, nil
+	}
+	s.setStream(stream)
+	s.updateMu.Unlock()
+	defer s.setStream(nil)
+
+	return s.client.receive(stream, func(msg *pb.SubscribeUpdate) error {
+		if err := fn(msg); err != nil {
+			return err
+		}
+		s.track(msg)
+		return nil
+	})
+}
+
+func (s *Subscription) setStream(stream pb.Geyser_SubscribeClient) {
+	s.mu.Lock()
+	s.stream = stream
+	s.mu.Unlock()
+}
+
+func (s *Subscription) resumeRequest() *pb.SubscribeRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	request := proto.Clone(s.request).(*pb.SubscribeRequest)
+	// Block and entry filters cannot be replayed, so such requests resume
+	// from the live tip.
+	if s.highestSlot > 0 && replayable(request) {
+		fromSlot := s.highestSlot - 1
+		request.FromSlot = &fromSlot
+	}
+	return request
+}
+
+func (s *Subscription) track(update *pb.SubscribeUpdate) {
+	slot, ok := UpdateSlot(update)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	if slot > s.highestSlot {
+		s.highestSlot = slot
+	}
+	s.mu.Unlock()
+}
+
+func wrapStreamError(err error) error {
+	if _, ok := err.(*GeyserGrpcClientError); ok {
+		return err
+	}
+	return NewGrpcStatusError(err)
+}
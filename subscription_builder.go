@@ -0,0 +1,392 @@
+package yellowstone
+
+import (
+	"errors"
+	"fmt"
+
+	pb "github.com/andrew-solarstorm/yellowstone-grpc-client-go/proto"
+	"github.com/gagliardetto/solana-go"
+	"google.golang.org/protobuf/proto"
+)
+
+type FilterError struct {
+	Kind    string
+	Name    string
+	Field   string
+	Message string
+}
+
+func (e *FilterError) Error() string {
+	location := e.Kind
+	if e.Name != "" {
+		location += "[" + e.Name + "]"
+	}
+	if e.Field != "" {
+		location += "." + e.Field
+	}
+	return location + ": " + e.Message
+}
+
+// SubscriptionBuilder assembles a SubscribeRequest. Filter builders embed it,
+// so calls can move from one filter to the next without leaving the chain.
+// Pubkey arguments accept base58 strings, solana.PublicKey, *solana.PublicKey
+// or 32-byte slices.
+type SubscriptionBuilder struct {
+	request *pb.SubscribeRequest
+	errs    []error
+}
+
+func NewSubscriptionBuilder() *SubscriptionBuilder {
+	return &SubscriptionBuilder{request: &pb.SubscribeRequest{}}
+}
+
+func (b *SubscriptionBuilder) fail(kind, name, field, format string, args ...any) {
+	b.errs = append(b.errs, &FilterError{
+		Kind:    kind,
+		Name:    name,
+		Field:   field,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (b *SubscriptionBuilder) pubkeys(kind, name, field string, keys []any) []string {
+	out := make([]string, 0, len(keys))
+	for _, key := range keys {
+		encoded, err := pubkeyString(key)
+		if err != nil {
+			b.fail(kind, name, field, "%v", err)
+			continue
+		}
+		out = append(out, encoded)
+	}
+	return out
+}
+
+func pubkeyString(key any) (string, error) {
+	switch k := key.(type) {
+	case string:
+		pk, err := solana.PublicKeyFromBase58(k)
+		if err != nil {
+			return "", fmt.Errorf("invalid pubkey %q: %w", k, err)
+		}
+		return pk.String(), nil
+	case solana.PublicKey:
+		return k.String(), nil
+	case *solana.PublicKey:
+		if k == nil {
+			return "", errors.New("nil pubkey")
+		}
+		return k.String(), nil
+	case []byte:
+		if len(k) != solana.PublicKeyLength {
+			return "", fmt.Errorf("invalid pubkey length %d", len(k))
+		}
+		return solana.PublicKeyFromBytes(k).String(), nil
+	default:
+		return "", fmt.Errorf("unsupported pubkey type %T", key)
+	}
+}
+
+func (b *SubscriptionBuilder) Commitment(level pb.CommitmentLevel) *SubscriptionBuilder {
+	b.request.Commitment = level.Enum()
+	return b
+}
+
+func (b *SubscriptionBuilder) FromSlot(slot uint64) *SubscriptionBuilder {
+	b.request.FromSlot = &slot
+	return b
+}
+
+func (b *SubscriptionBuilder) AccountsDataSlice(offset, length uint64) *SubscriptionBuilder {
+	b.request.AccountsDataSlice = append(b.request.AccountsDataSlice, &pb.SubscribeRequestAccountsDataSlice{
+		Offset: offset,
+		Length: length,
+	})
+	return b
+}
+
+// Build returns a copy of the request, so the builder can keep being chained
+// without changing requests it already returned.
+func (b *SubscriptionBuilder) Build() (*pb.SubscribeRequest, error) {
+	if err := errors.Join(b.errs...); err != nil {
+		return nil, err
+	}
+	if err := ValidateSubscribeRequest(b.request); err != nil {
+		return nil, err
+	}
+	return proto.Clone(b.request).(*pb.SubscribeRequest), nil
+}
+
+type AccountsFilterBuilder struct {
+	*SubscriptionBuilder
+	name   string
+	filter *pb.SubscribeRequestFilterAccounts
+}
+
+func (b *SubscriptionBuilder) Accounts(name string) *AccountsFilterBuilder {
+	if b.request.Accounts == nil {
+		b.request.Accounts = make(map[string]*pb.SubscribeRequestFilterAccounts)
+	}
+	filter, ok := b.request.Accounts[name]
+	if !ok {
+		filter = &pb.SubscribeRequestFilterAccounts{}
+		b.request.Accounts[name] = filter
+	}
+	return &AccountsFilterBuilder{SubscriptionBuilder: b, name: name, filter: filter}
+}
+
+func (a *AccountsFilterBuilder) Account(keys ...any) *AccountsFilterBuilder {
+	a.filter.Account = append(a.filter.Account, a.pubkeys("accounts", a.name, "account", keys)...)
+	return a
+}
+
+func (a *AccountsFilterBuilder) Owner(keys ...any) *AccountsFilterBuilder {
+	a.filter.Owner = append(a.filter.Owner, a.pubkeys("accounts", a.name, "owner", keys)...)
+	return a
+}
+
+func (a *AccountsFilterBuilder) Memcmp(offset uint64, data []byte) *AccountsFilterBuilder {
+	return a.addFilter(&pb.SubscribeRequestFilterAccountsFilter{
+		Filter: &pb.SubscribeRequestFilterAccountsFilter_Memcmp{
+			Memcmp: &pb.SubscribeRequestFilterAccountsFilterMemcmp{
+				Offset: offset,
+				Data:   &pb.SubscribeRequestFilterAccountsFilterMemcmp_Bytes{Bytes: data},
+			},
+		},
+	})
+}
+
+func (a *AccountsFilterBuilder) MemcmpBase58(offset uint64, data string) *AccountsFilterBuilder {
+	return a.addFilter(&pb.SubscribeRequestFilterAccountsFilter{
+		Filter: &pb.SubscribeRequestFilterAccountsFilter_Memcmp{
+			Memcmp: &pb.SubscribeRequestFilterAccountsFilterMemcmp{
+				Offset: offset,
+				Data:   &pb.SubscribeRequestFilterAccountsFilterMemcmp_Base58{Base58: data},
+			},
+		},
+	})
+}
+
+func (a *AccountsFilterBuilder) MemcmpPubkey(offset uint64, key any) *AccountsFilterBuilder {
+	encoded, err := pubkeyString(key)
+	if err != nil {
+		a.fail("accounts", a.name, "memcmp", "%v", err)
+		return a
+	}
+	return a.MemcmpBase58(offset, encoded)
+}
+
+func (a *AccountsFilterBuilder) DataSize(size uint64) *AccountsFilterBuilder {
+	return a.addFilter(&pb.SubscribeRequestFilterAccountsFilter{
+		Filter: &pb.SubscribeRequestFilterAccountsFilter_Datasize{Datasize: size},
+	})
+}
+
+func (a *AccountsFilterBuilder) TokenAccountState() *AccountsFilterBuilder {
+	return a.addFilter(&pb.SubscribeRequestFilterAccountsFilter{
+		Filter: &pb.SubscribeRequestFilterAccountsFilter_TokenAccountState{TokenAccountState: true},
+	})
+}
+
+func (a *AccountsFilterBuilder) LamportsEq(value uint64) *AccountsFilterBuilder {
+	return a.lamports(&pb.SubscribeRequestFilterAccountsFilterLamports{
+		Cmp: &pb.SubscribeRequestFilterAccountsFilterLamports_Eq{Eq: value},
+	})
+}
+
+func (a *AccountsFilterBuilder) LamportsNe(value uint64) *AccountsFilterBuilder {
+	return a.lamports(&pb.SubscribeRequestFilterAccountsFilterLamports{
+		Cmp: &pb.SubscribeRequestFilterAccountsFilterLamports_Ne{Ne: value},
+	})
+}
+
+func (a *AccountsFilterBuilder) LamportsLt(value uint64) *AccountsFilterBuilder {
+	return a.lamports(&pb.SubscribeRequestFilterAccountsFilterLamports{
+		Cmp: &pb.SubscribeRequestFilterAccountsFilterLamports_Lt{Lt: value},
+	})
+}
+
+func (a *AccountsFilterBuilder) LamportsGt(value uint64) *AccountsFilterBuilder {
+	return a.lamports(&pb.SubscribeRequestFilterAccountsFilterLamports{
+		Cmp: &pb.SubscribeRequestFilterAccountsFilterLamports_Gt{Gt: value},
+	})
+}
+
+func (a *AccountsFilterBuilder) NonemptyTxnSignature(enabled bool) *AccountsFilterBuilder {
+	a.filter.NonemptyTxnSignature = &enabled
+	return a
+}
+
+func (a *AccountsFilterBuilder) lamports(cmp *pb.SubscribeRequestFilterAccountsFilterLamports) *AccountsFilterBuilder {
+	return a.addFilter(&pb.SubscribeRequestFilterAccountsFilter{
+		Filter: &pb.SubscribeRequestFilterAccountsFilter_Lamports{Lamports: cmp},
+	})
+}
+
+func (a *AccountsFilterBuilder) addFilter(filter *pb.SubscribeRequestFilterAccountsFilter) *AccountsFilterBuilder {
+	a.filter.Filters = append(a.filter.Filters, filter)
+	return a
+}
+
+type TransactionsFilterBuilder struct {
+	*SubscriptionBuilder
+	kind   string
+	name   string
+	filter *pb.SubscribeRequestFilterTransactions
+}
+
+func (b *SubscriptionBuilder) Transactions(name string) *TransactionsFilterBuilder {
+	if b.request.Transactions == nil {
+		b.request.Transactions = make(map[string]*pb.SubscribeRequestFilterTransactions)
+	}
+	return b.transactions("transactions", name, b.request.Transactions)
+}
+
+func (b *SubscriptionBuilder) TransactionsStatus(name string) *TransactionsFilterBuilder {
+	if b.request.TransactionsStatus == nil {
+		b.request.TransactionsStatus = make(map[string]*pb.SubscribeRequestFilterTransactions)
+	}
+	return b.transactions("transactions_status", name, b.request.TransactionsStatus)
+}
+
+func (b *SubscriptionBuilder) transactions(
+	kind, name string,
+	filters map[string]*pb.SubscribeRequestFilterTransactions,
+) *TransactionsFilterBuilder {
+	filter, ok := filters[name]
+	if !ok {
+		filter = &pb.SubscribeRequestFilterTransactions{}
+		filters[name] = filter
+	}
+	return &TransactionsFilterBuilder{SubscriptionBuilder: b, kind: kind, name: name, filter: filter}
+}
+
+func (t *TransactionsFilterBuilder) Vote(vote bool) *TransactionsFilterBuilder {
+	t.filter.Vote = &vote
+	return t
+}
+
+func (t *TransactionsFilterBuilder) Failed(failed bool) *TransactionsFilterBuilder {
+	t.filter.Failed = &failed
+	return t
+}
+
+func (t *TransactionsFilterBuilder) Signature(signature any) *TransactionsFilterBuilder {
+	var encoded string
+	switch s := signature.(type) {
+	case string:
+		sig, err := solana.SignatureFromBase58(s)
+		if err != nil {
+			t.fail(t.kind, t.name, "signature", "invalid signature %q: %v", s, err)
+			return t
+		}
+		encoded = sig.String()
+	case solana.Signature:
+		encoded = s.String()
+	default:
+		t.fail(t.kind, t.name, "signature", "unsupported signature type %T", signature)
+		return t
+	}
+	t.filter.Signature = &encoded
+	return t
+}
+
+func (t *TransactionsFilterBuilder) Include(keys ...any) *TransactionsFilterBuilder {
+	t.filter.AccountInclude = append(t.filter.AccountInclude, t.pubkeys(t.kind, t.name, "account_include", keys)...)
+	return t
+}
+
+func (t *TransactionsFilterBuilder) Exclude(keys ...any) *TransactionsFilterBuilder {
+	t.filter.AccountExclude = append(t.filter.AccountExclude, t.pubkeys(t.kind, t.name, "account_exclude", keys)...)
+	return t
+}
+
+func (t *TransactionsFilterBuilder) Required(keys ...any) *TransactionsFilterBuilder {
+	t.filter.AccountRequired = append(t.filter.AccountRequired, t.pubkeys(t.kind, t.name, "account_required", keys)...)
+	return t
+}
+
+type SlotsFilterBuilder struct {
+	*SubscriptionBuilder
+	filter *pb.SubscribeRequestFilterSlots
+}
+
+func (b *SubscriptionBuilder) Slots(name string) *SlotsFilterBuilder {
+	if b.request.Slots == nil {
+		b.request.Slots = make(map[string]*pb.SubscribeRequestFilterSlots)
+	}
+	filter, ok := b.request.Slots[name]
+	if !ok {
+		filter = &pb.SubscribeRequestFilterSlots{}
+		b.request.Slots[name] = filter
+	}
+	return &SlotsFilterBuilder{SubscriptionBuilder: b, filter: filter}
+}
+
+func (s *SlotsFilterBuilder) FilterByCommitment(enabled bool) *SlotsFilterBuilder {
+	s.filter.FilterByCommitment = &enabled
+	return s
+}
+
+func (s *SlotsFilterBuilder) InterslotUpdates(enabled bool) *SlotsFilterBuilder {
+	s.filter.InterslotUpdates = &enabled
+	return s
+}
+
+type BlocksFilterBuilder struct {
+	*SubscriptionBuilder
+	name   string
+	filter *pb.SubscribeRequestFilterBlocks
+}
+
+func (b *SubscriptionBuilder) Blocks(name string) *BlocksFilterBuilder {
+	if b.request.Blocks == nil {
+		b.request.Blocks = make(map[string]*pb.SubscribeRequestFilterBlocks)
+	}
+	filter, ok := b.request.Blocks[name]
+	if !ok {
+		filter = &pb.SubscribeRequestFilterBlocks{}
+		b.request.Blocks[name] = filter
+	}
+	return &BlocksFilterBuilder{SubscriptionBuilder: b, name: name, filter: filter}
+}
+
+func (k *BlocksFilterBuilder) AccountInclude(keys ...any) *BlocksFilterBuilder {
+	k.filter.AccountInclude = append(k.filter.AccountInclude, k.pubkeys("blocks", k.name, "account_include", keys)...)
+	return k
+}
+
+func (k *BlocksFilterBuilder) IncludeTransactions(enabled bool) *BlocksFilterBuilder {
+	k.filter.IncludeTransactions = &enabled
+	return k
+}
+
+func (k *BlocksFilterBuilder) IncludeAccounts(enabled bool) *BlocksFilterBuilder {
+	k.filter.IncludeAccounts = &enabled
+	return k
+}
+
+func (k *BlocksFilterBuilder) IncludeEntries(enabled bool) *BlocksFilterBuilder {
+	k.filter.IncludeEntries = &enabled
+	return k
+}
+
+func (b *SubscriptionBuilder) BlocksMeta(name string) *SubscriptionBuilder {
+	if b.request.BlocksMeta == nil {
+		b.request.BlocksMeta = make(map[string]*pb.SubscribeRequestFilterBlocksMeta)
+	}
+	if _, ok := b.request.BlocksMeta[name]; !ok {
+		b.request.BlocksMeta[name] = &pb.SubscribeRequestFilterBlocksMeta{}
+	}
+	return b
+}
+
+func (b *SubscriptionBuilder) Entry(name string) *SubscriptionBuilder {
+	if b.request.Entry == nil {
+		b.request.Entry = make(map[string]*pb.SubscribeRequestFilterEntry)
+	}
+	if _, ok := b.request.Entry[name]; !ok {
+		b.request.Entry[name] = &pb.SubscribeRequestFilterEntry{}
+	}
+	return b
+}
@@ -0,0 +1,95 @@
+package yellowstone
+
+import (
+	"errors"
+	"testing"
+
+	pb "github.com/andrew-solarstorm/yellowstone-grpc-client-go/proto"
+	"github.com/gagliardetto/solana-go"
+)
+
+func TestSubscriptionBuilder(t *testing.T) {
+	owner := solana.TokenProgramID
+	mint := solana.WrappedSol
+
+	req, err := NewSubscriptionBuilder().
+		Accounts("tokens").
+		Owner(owner).
+		Memcmp(0, mint.Bytes()).
+		DataSize(165).
+		Transactions("swaps").
+		Include(owner.String(), &mint).
+		Vote(false).
+		Failed(false).
+		Slots("slot").
+		FilterByCommitment(true).
+		Commitment(pb.CommitmentLevel_CONFIRMED).
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	accounts := req.Accounts["tokens"]
+	if accounts == nil || len(accounts.Owner) != 1 || accounts.Owner[0] != owner.String() {
+		t.Fatalf("Unexpected accounts filter: %v", accounts)
+	}
+	if len(accounts.Filters) != 2 {
+		t.Fatalf("Expected memcmp and datasize filters, got %d", len(accounts.Filters))
+	}
+	if memcmp := accounts.Filters[0].GetMemcmp(); memcmp == nil || string(memcmp.GetBytes()) != string(mint.Bytes()) {
+		t.Errorf("Unexpected memcmp filter: %v", accounts.Filters[0])
+	}
+	if accounts.Filters[1].GetDatasize() != 165 {
+		t.Errorf("Unexpected datasize filter: %v", accounts.Filters[1])
+	}
+
+	txs := req.Transactions["swaps"]
+	if txs == nil || len(txs.AccountInclude) != 2 || txs.Vote == nil || *txs.Vote || txs.Failed == nil || *txs.Failed {
+		t.Fatalf("Unexpected transactions filter: %v", txs)
+	}
+	if txs.AccountInclude[1] != mint.String() {
+		t.Errorf("Expected mint in account include, got %v", txs.AccountInclude)
+	}
+
+	if !req.Slots["slot"].GetFilterByCommitment() {
+		t.Error("Expected slot filter by commitment")
+	}
+	if req.GetCommitment() != pb.CommitmentLevel_CONFIRMED {
+		t.Errorf("Expected CONFIRMED commitment, got %v", req.GetCommitment())
+	}
+}
+
+func TestSubscriptionBuilderInvalidPubkey(t *testing.T) {
+	_, err := NewSubscriptionBuilder().
+		Accounts("bad").
+		Account("not-a-pubkey").
+		Build()
+
+	var filterErr *FilterError
+	if !errors.As(err, &filterErr) {
+		t.Fatalf("Expected FilterError, got %v", err)
+	}
+	if filterErr.Kind != "accounts" || filterErr.Name != "bad" || filterErr.Field != "account" {
+		t.Errorf("Unexpected filter error location: %+v", filterErr)
+	}
+}
+
+func TestSubscriptionBuilderBuildReturnsCopy(t *testing.T) {
+	builder := NewSubscriptionBuilder()
+	first, err := builder.Slots("slot").Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	second, err := builder.Slots("other").Commitment(pb.CommitmentLevel_FINALIZED).Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	if len(first.Slots) != 1 || first.Commitment != nil {
+		t.Fatalf("Later calls changed a built request: %v", first)
+	}
+	if len(second.Slots) != 2 || second.GetCommitment() != pb.CommitmentLevel_FINALIZED {
+		t.Fatalf("Unexpected second request: %v", second)
+	}
+}
@@ -0,0 +1,114 @@
+package yellowstone
+
+import (
+	"slices"
+
+	pb "github.com/andrew-solarstorm/yellowstone-grpc-client-go/proto"
+	"google.golang.org/protobuf/proto"
+)
+
+func (s *Subscription) AddAccounts(name string, keys ...any) error {
+	encoded := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pubkey, err := pubkeyString(key)
+		if err != nil {
+			return &FilterError{Kind: "accounts", Name: name, Field: "account", Message: err.Error()}
+		}
+		encoded = append(encoded, pubkey)
+	}
+
+	return s.update(func(request *pb.SubscribeRequest) {
+		if request.Accounts == nil {
+			request.Accounts = make(map[string]*pb.SubscribeRequestFilterAccounts)
+		}
+		filter, ok := request.Accounts[name]
+		if !ok {
+			filter = &pb.SubscribeRequestFilterAccounts{}
+			request.Accounts[name] = filter
+		}
+		for _, key := range encoded {
+			if !slices.Contains(filter.Account, key) {
+				filter.Account = append(filter.Account, key)
+			}
+		}
+	})
+}
+
+// RemoveAccounts drops keys from the named accounts filter. A filter left
+// without any criteria is deleted rather than widened to every account.
+func (s *Subscription) RemoveAccounts(name string, keys ...any) error {
+	remove := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		pubkey, err := pubkeyString(key)
+		if err != nil {
+			return &FilterError{Kind: "accounts", Name: name, Field: "account", Message: err.Error()}
+		}
+		remove[pubkey] = struct{}{}
+	}
+
+	return s.update(func(request *pb.SubscribeRequest) {
+		filter, ok := request.Accounts[name]
+		if !ok {
+			return
+		}
+		filter.Account = slices.DeleteFunc(filter.Account, func(key string) bool {
+			_, found := remove[key]
+			return found
+		})
+		if len(filter.Account) == 0 && len(filter.Owner) == 0 && len(filter.Filters) == 0 {
+			delete(request.Accounts, name)
+		}
+	})
+}
+
+func (s *Subscription) SetTransactionFilter(name string, filter *pb.SubscribeRequestFilterTransactions) error {
+	return s.update(func(request *pb.SubscribeRequest) {
+		if filter == nil {
+			delete(request.Transactions, name)
+			return
+		}
+		if request.Transactions == nil {
+			request.Transactions = make(map[string]*pb.SubscribeRequestFilterTransactions)
+		}
+		request.Transactions[name] = proto.Clone(filter).(*pb.SubscribeRequestFilterTransactions)
+	})
+}
+
+func (s *Subscription) Replace(request *pb.SubscribeRequest) error {
+	return s.update(func(current *pb.SubscribeRequest) {
+		proto.Reset(current)
+		proto.Merge(current, request)
+	})
+}
+
+// update applies modify to a copy of the current request, validates its
+// filters, of which there may be none left, and, when a stream is open, sends
+// the full request on it. The stored state changes even if the send fails so
+// that the next reconnect picks it up.
+func (s *Subscription) update(modify func(*pb.SubscribeRequest)) error {
+	s.updateMu.Lock()
+	defer s.updateMu.Unlock()
+
+	s.mu.Lock()
+	next := proto.Clone(s.request).(*pb.SubscribeRequest)
+	modify(next)
+	next.Ping = nil
+	if err := validateSubscribeFilters(next); err != nil {
+		s.mu.Unlock()
+		return NewInvalidSubscribeRequestError(err)
+	}
+	s.request = next
+	stream := s.stream
+	s.mu.Unlock()
+
+	if stream == nil {
+		return nil
+	}
+
+	live := proto.Clone(next).(*pb.SubscribeRequest)
+	live.FromSlot = nil
+	if err := stream.Send(live); err != nil {
+		return NewSubscribeSendError(err)
+	}
+	return nil
+}
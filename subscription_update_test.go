@@ -0,0 +1,181 @@
+package yellowstone
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	pb "github.com/andrew-solarstorm/yellowstone-grpc-client-go/proto"
+	"github.com/gagliardetto/solana-go"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSubscriptionLiveFilterUpdates(t *testing.T) {
+	keys := []solana.PublicKey{solana.SystemProgramID, solana.TokenProgramID, solana.WrappedSol, solana.SysVarClockPubkey}
+
+	var (
+		mu      sync.Mutex
+		streams [][]*pb.SubscribeRequest
+	)
+	received := make(chan struct{}, 16)
+
+	srv := &testGeyserServer{
+		subscribe: func(stream pbSubscribeServer) error {
+			mu.Lock()
+			streams = append(streams, nil)
+			index := len(streams) - 1
+			mu.Unlock()
+
+			req, err := stream.Recv()
+			if err != nil {
+				return err
+			}
+			mu.Lock()
+			streams[index] = append(streams[index], req)
+			mu.Unlock()
+
+			if index > 0 {
+				<-stream.Context().Done()
+				return nil
+			}
+
+			if err := stream.Send(slotUpdate(5)); err != nil {
+				return err
+			}
+			for i := 0; i < len(keys)+1; i++ {
+				req, err := stream.Recv()
+				if err != nil {
+					return err
+				}
+				mu.Lock()
+				streams[index] = append(streams[index], req)
+				mu.Unlock()
+				received <- struct{}{}
+			}
+			return status.Error(codes.Unavailable, "restart")
+		},
+	}
+
+	client := connectTestClient(t, startTestServer(t, srv))
+	sub := client.SubscribeWithReconnect(slotsRequest(), ReconnectPolicy{InitialBackoff: time.Millisecond})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	updated := make(chan error, len(keys)+1)
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- sub.Run(ctx, func(update *pb.SubscribeUpdate) error {
+			if update.GetSlot().GetSlot() != 5 {
+				return nil
+			}
+			var wg sync.WaitGroup
+			for _, key := range keys {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					updated <- sub.AddAccounts("watched", key)
+				}()
+			}
+			wg.Wait()
+			updated <- sub.SetTransactionFilter("txs", &pb.SubscribeRequestFilterTransactions{
+				AccountInclude: []string{keys[0].String()},
+			})
+			return nil
+		})
+	}()
+
+	for i := 0; i < len(keys)+1; i++ {
+		select {
+		case err := <-updated:
+			if err != nil {
+				t.Fatalf("Filter update failed: %v", err)
+			}
+		case <-ctx.Done():
+			t.Fatal("Timed out waiting for filter updates")
+		}
+	}
+	for i := 0; i < len(keys)+1; i++ {
+		select {
+		case <-received:
+		case <-ctx.Done():
+			t.Fatal("Timed out waiting for server to receive updates")
+		}
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		mu.Lock()
+		reconnected := len(streams) == 2 && len(streams[1]) == 1
+		mu.Unlock()
+		if reconnected || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	<-runErr
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(streams) != 2 || len(streams[1]) != 1 {
+		t.Fatalf("Expected a reconnect with one request, got %d streams", len(streams))
+	}
+
+	last := streams[0][len(streams[0])-1]
+	if len(last.Accounts["watched"].GetAccount()) != len(keys) || last.Transactions["txs"] == nil {
+		t.Errorf("Expected final live request to contain all filters, got %v", last)
+	}
+	if last.FromSlot != nil {
+		t.Error("Expected live updates to omit FromSlot")
+	}
+
+	restored := streams[1][0]
+	if len(restored.Accounts["watched"].GetAccount()) != len(keys) || restored.Transactions["txs"] == nil {
+		t.Errorf("Expected reconnect to restore filters, got %v", restored)
+	}
+
+	if err := sub.RemoveAccounts("watched", keys[0], keys[1], keys[2], keys[3]); err != nil {
+		t.Fatalf("RemoveAccounts failed: %v", err)
+	}
+	if _, ok := sub.Request().Accounts["watched"]; ok {
+		t.Error("Expected empty accounts filter to be removed")
+	}
+}
+
+func TestSubscriptionUpdateRejectsInvalidFilter(t *testing.T) {
+	client := connectTestClient(t, "127.0.0.1:1")
+	sub := client.SubscribeWithReconnect(slotsRequest(), DefaultReconnectPolicy())
+
+	if err := sub.AddAccounts("bad", "not-a-key"); err == nil {
+		t.Fatal("Expected invalid pubkey to be rejected")
+	}
+
+	err := sub.SetTransactionFilter("conflict", &pb.SubscribeRequestFilterTransactions{
+		AccountInclude: []string{solana.SystemProgramID.String()},
+		AccountExclude: []string{solana.SystemProgramID.String()},
+	})
+	if err == nil {
+		t.Fatal("Expected conflicting filter to be rejected")
+	}
+	if _, ok := sub.Request().Transactions["conflict"]; ok {
+		t.Error("Expected rejected filter not to be stored")
+	}
+
+	invalid := &pb.SubscribeRequest{
+		Accounts: map[string]*pb.SubscribeRequestFilterAccounts{"bad": {Owner: []string{"not-a-key"}}},
+	}
+	if err := sub.Replace(invalid); err == nil {
+		t.Fatal("Expected invalid replacement to be rejected")
+	}
+	if len(sub.Request().Slots) != 1 {
+		t.Error("Expected original request to be kept after rejected Replace")
+	}
+
+	if err := sub.Replace(&pb.SubscribeRequest{}); err != nil {
+		t.Errorf("Expected a subscription to drop all filters, got %v", err)
+	}
+}
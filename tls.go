@@ -0,0 +1,94 @@
+package yellowstone
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"os"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func (b *GeyserGrpcBuilder) transportCredentials(httpMode bool) (credentials.TransportCredentials, error) {
+	if b.tlsConfig != nil {
+		return *b.tlsConfig, nil
+	}
+
+	if !b.hasTLSOptions() {
+		if httpMode {
+			return insecure.NewCredentials(), nil
+		}
+		pool, _ := x509.SystemCertPool()
+		return credentials.NewClientTLSFromCert(pool, ""), nil
+	}
+
+	config, err := b.buildTLSConfig()
+	if err != nil {
+		return nil, NewTLSConfigError(err)
+	}
+	return credentials.NewTLS(config), nil
+}
+
+func (b *GeyserGrpcBuilder) hasTLSOptions() bool {
+	return b.tlsClientConfig != nil ||
+		b.tlsCACertFile != "" ||
+		len(b.tlsCACertPEM) > 0 ||
+		b.tlsClientCertFile != "" ||
+		len(b.tlsClientCertPEM) > 0 ||
+		b.tlsServerName != ""
+}
+
+func (b *GeyserGrpcBuilder) buildTLSConfig() (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if b.tlsClientConfig != nil {
+		config = b.tlsClientConfig.Clone()
+	}
+
+	caPEM := b.tlsCACertPEM
+	if b.tlsCACertFile != "" {
+		data, err := os.ReadFile(b.tlsCACertFile)
+		if err != nil {
+			return nil, err
+		}
+		caPEM = append(append([]byte{}, caPEM...), data...)
+	}
+
+	// A custom CA bundle replaces the system roots rather than extending
+	// them; it only extends the RootCAs of a caller supplied tls.Config.
+	if len(caPEM) > 0 {
+		pool := x509.NewCertPool()
+		if config.RootCAs != nil {
+			pool = config.RootCAs.Clone()
+		}
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, errors.New("no certificates found in CA bundle")
+		}
+		config.RootCAs = pool
+	}
+
+	certPEM, keyPEM := b.tlsClientCertPEM, b.tlsClientKeyPEM
+	if b.tlsClientCertFile != "" {
+		var err error
+		if certPEM, err = os.ReadFile(b.tlsClientCertFile); err != nil {
+			return nil, err
+		}
+		if keyPEM, err = os.ReadFile(b.tlsClientKeyFile); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(certPEM) > 0 || len(keyPEM) > 0 {
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = append(config.Certificates, cert)
+	}
+
+	if b.tlsServerName != "" {
+		config.ServerName = b.tlsServerName
+	}
+
+	return config, nil
+}
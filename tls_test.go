@@ -0,0 +1,257 @@
+package yellowstone
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+type testCert struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+func newTestCert(t *testing.T, template *x509.Certificate, parent *testCert) *testCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+
+	template.SerialNumber = big.NewInt(time.Now().UnixNano())
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+
+	signer, signerKey := template, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatalf("CreateCertificate failed: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("ParseCertificate failed: %v", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalECPrivateKey failed: %v", err)
+	}
+
+	return &testCert{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+type testPKI struct {
+	ca     *testCert
+	server *testCert
+	client *testCert
+}
+
+func newTestPKI(t *testing.T) *testPKI {
+	ca := newTestCert(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "test ca"},
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}, nil)
+
+	server := newTestCert(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "geyser.test"},
+		DNSNames:    []string{"geyser.test"},
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, ca)
+
+	client := newTestCert(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "client"},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, ca)
+
+	return &testPKI{ca: ca, server: server, client: client}
+}
+
+func startTLSTestServer(t *testing.T, pki *testPKI, requireClientCert bool) string {
+	t.Helper()
+
+	serverCert, err := tls.X509KeyPair(pki.server.certPEM, pki.server.keyPEM)
+	if err != nil {
+		t.Fatalf("X509KeyPair failed: %v", err)
+	}
+
+	config := &tls.Config{Certificates: []tls.Certificate{serverCert}}
+	if requireClientCert {
+		pool := x509.NewCertPool()
+		pool.AddCert(pki.ca.cert)
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return startTestServer(t, &testGeyserServer{}, grpc.Creds(credentials.NewTLS(config)))
+}
+
+func getTestVersion(t *testing.T, builder *GeyserGrpcBuilder) error {
+	t.Helper()
+
+	client, err := builder.ConnectLazy()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err = client.GetVersion(ctx)
+	return err
+}
+
+func TestTLSCustomCA(t *testing.T) {
+	pki := newTestPKI(t)
+	addr := startTLSTestServer(t, pki, false)
+
+	if err := getTestVersion(t, BuildFromStatic("https://"+addr).TLSCACert(pki.ca.certPEM)); err != nil {
+		t.Fatalf("Expected GetVersion to succeed with custom CA, got %v", err)
+	}
+
+	if err := getTestVersion(t, BuildFromStatic("https://"+addr)); err == nil {
+		t.Fatal("Expected GetVersion to fail without custom CA")
+	}
+}
+
+func TestTLSCACertFileAndServerName(t *testing.T) {
+	pki := newTestPKI(t)
+	addr := startTLSTestServer(t, pki, false)
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, pki.ca.certPEM, 0o600); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	builder := BuildFromStatic("https://" + addr).
+		TLSCACertFile(caFile).
+		TLSServerName("geyser.test")
+	if err := getTestVersion(t, builder); err != nil {
+		t.Fatalf("Expected GetVersion to succeed, got %v", err)
+	}
+
+	builder = BuildFromStatic("https://" + addr).
+		TLSCACertFile(caFile).
+		TLSServerName("other.test")
+	if err := getTestVersion(t, builder); err == nil {
+		t.Fatal("Expected GetVersion to fail with mismatched server name")
+	}
+}
+
+func TestTLSMutualAuth(t *testing.T) {
+	pki := newTestPKI(t)
+	addr := startTLSTestServer(t, pki, true)
+
+	builder := BuildFromStatic("https://"+addr).
+		TLSCACert(pki.ca.certPEM).
+		TLSClientCert(pki.client.certPEM, pki.client.keyPEM)
+	if err := getTestVersion(t, builder); err != nil {
+		t.Fatalf("Expected GetVersion to succeed with client cert, got %v", err)
+	}
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "client.pem")
+	keyFile := filepath.Join(dir, "client.key")
+	os.WriteFile(certFile, pki.client.certPEM, 0o600)
+	os.WriteFile(keyFile, pki.client.keyPEM, 0o600)
+
+	builder = BuildFromStatic("https://"+addr).
+		TLSCACert(pki.ca.certPEM).
+		TLSClientCertFile(certFile, keyFile)
+	if err := getTestVersion(t, builder); err != nil {
+		t.Fatalf("Expected GetVersion to succeed with client cert files, got %v", err)
+	}
+
+	if err := getTestVersion(t, BuildFromStatic("https://"+addr).TLSCACert(pki.ca.certPEM)); err == nil {
+		t.Fatal("Expected GetVersion to fail without client cert")
+	}
+}
+
+func TestTLSConfigPassthrough(t *testing.T) {
+	pki := newTestPKI(t)
+	addr := startTLSTestServer(t, pki, false)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(pki.ca.cert)
+
+	if err := getTestVersion(t, BuildFromStatic("https://"+addr).TLSClientConfig(&tls.Config{RootCAs: pool})); err != nil {
+		t.Fatalf("Expected GetVersion to succeed with tls.Config, got %v", err)
+	}
+
+	creds := credentials.NewClientTLSFromCert(pool, "")
+	if err := getTestVersion(t, BuildFromStatic("https://"+addr).TLSConfig(creds)); err != nil {
+		t.Fatalf("Expected GetVersion to succeed with TLSConfig, got %v", err)
+	}
+}
+
+func TestTLSInvalidCAFile(t *testing.T) {
+	_, err := BuildFromStatic("https://127.0.0.1:1").
+		TLSCACertFile(filepath.Join(t.TempDir(), "missing.pem")).
+		ConnectLazy()
+
+	var builderErr *GeyserGrpcBuilderError
+	if !errors.As(err, &builderErr) || builderErr.Type != "TLSConfigError" {
+		t.Fatalf("Expected TLSConfigError, got %v", err)
+	}
+}
+
+func TestTLSCustomCAReplacesSystemRoots(t *testing.T) {
+	pki := newTestPKI(t)
+
+	config, err := BuildFromStatic("https://geyser.test").TLSCACert(pki.ca.certPEM).buildTLSConfig()
+	if err != nil {
+		t.Fatalf("buildTLSConfig failed: %v", err)
+	}
+	want := x509.NewCertPool()
+	want.AddCert(pki.ca.cert)
+	if !config.RootCAs.Equal(want) {
+		t.Fatal("Expected the custom CA to be the only root")
+	}
+
+	base := x509.NewCertPool()
+	base.AddCert(pki.client.cert)
+	config, err = BuildFromStatic("https://geyser.test").
+		TLSClientConfig(&tls.Config{RootCAs: base}).
+		TLSCACert(pki.ca.certPEM).
+		buildTLSConfig()
+	if err != nil {
+		t.Fatalf("buildTLSConfig failed: %v", err)
+	}
+	want = base.Clone()
+	want.AddCert(pki.ca.cert)
+	if !config.RootCAs.Equal(want) {
+		t.Fatal("Expected the custom CA to extend the caller's RootCAs")
+	}
+}
@@ -0,0 +1,266 @@
+package token
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+type accountType uint8
+
+const (
+	accountTypeMint accountType = iota + 1
+	accountTypeAccount
+)
+
+type ExtensionType uint16
+
+const (
+	ExtensionUninitialized ExtensionType = iota
+	ExtensionTransferFeeConfig
+	ExtensionTransferFeeAmount
+	ExtensionMintCloseAuthority
+	ExtensionConfidentialTransferMint
+	ExtensionConfidentialTransferAccount
+	ExtensionDefaultAccountState
+	ExtensionImmutableOwner
+	ExtensionMemoTransfer
+	ExtensionNonTransferable
+	ExtensionInterestBearingConfig
+	ExtensionCpiGuard
+	ExtensionPermanentDelegate
+	ExtensionNonTransferableAccount
+	ExtensionTransferHook
+	ExtensionTransferHookAccount
+	ExtensionConfidentialTransferFeeConfig
+	ExtensionConfidentialTransferFeeAmount
+	ExtensionMetadataPointer
+	ExtensionTokenMetadata
+	ExtensionGroupPointer
+	ExtensionTokenGroup
+	ExtensionGroupMemberPointer
+	ExtensionTokenGroupMember
+)
+
+// Extension is one TLV entry of a Token-2022 mint or account. Value holds the
+// decoded form for the supported types and is nil otherwise; Data always
+// holds the raw bytes.
+type Extension struct {
+	Type  ExtensionType
+	Data  []byte
+	Value any
+}
+
+type TransferFee struct {
+	Epoch                  uint64
+	MaximumFee             uint64
+	TransferFeeBasisPoints uint16
+}
+
+type TransferFeeConfig struct {
+	TransferFeeConfigAuthority *solana.PublicKey
+	WithdrawWithheldAuthority  *solana.PublicKey
+	WithheldAmount             uint64
+	OlderTransferFee           TransferFee
+	NewerTransferFee           TransferFee
+}
+
+// Fee returns the fee in effect at epoch.
+func (c *TransferFeeConfig) Fee(epoch uint64) TransferFee {
+	if epoch >= c.NewerTransferFee.Epoch {
+		return c.NewerTransferFee
+	}
+	return c.OlderTransferFee
+}
+
+type TransferFeeAmount struct {
+	WithheldAmount uint64
+}
+
+type MetadataPointer struct {
+	Authority       *solana.PublicKey
+	MetadataAddress *solana.PublicKey
+}
+
+type TokenMetadata struct {
+	UpdateAuthority    *solana.PublicKey
+	Mint               solana.PublicKey
+	Name               string
+	Symbol             string
+	URI                string
+	AdditionalMetadata [][2]string
+}
+
+const (
+	transferFeeConfigSize = 108
+	transferFeeAmountSize = 8
+	metadataPointerSize   = 64
+)
+
+// decodeExtensions reads the TLV entries that follow the account type byte.
+// Mints are padded to the account size so both kinds share the same offset.
+func decodeExtensions(data []byte, want accountType) ([]Extension, error) {
+	if len(data) <= AccountSize {
+		return nil, nil
+	}
+	if got := accountType(data[AccountSize]); got != want {
+		return nil, fmt.Errorf("account type %d does not match expected %d", got, want)
+	}
+
+	var extensions []Extension
+	for pos := AccountSize + 1; pos+4 <= len(data); {
+		typ := ExtensionType(binary.LittleEndian.Uint16(data[pos:]))
+		length := int(binary.LittleEndian.Uint16(data[pos+2:]))
+		pos += 4
+
+		if typ == ExtensionUninitialized && length == 0 {
+			break
+		}
+		if pos+length > len(data) {
+			return nil, fmt.Errorf("extension %d length %d overflows account data", typ, length)
+		}
+
+		value := data[pos : pos+length]
+		pos += length
+
+		decoded, err := decodeExtension(typ, value)
+		if err != nil {
+			return nil, fmt.Errorf("extension %d: %w", typ, err)
+		}
+		extensions = append(extensions, Extension{Type: typ, Data: value, Value: decoded})
+	}
+	return extensions, nil
+}
+
+func decodeExtension(typ ExtensionType, data []byte) (any, error) {
+	switch typ {
+	case ExtensionTransferFeeConfig:
+		if len(data) != transferFeeConfigSize {
+			return nil, fmt.Errorf("expected %d bytes, got %d", transferFeeConfigSize, len(data))
+		}
+		r := &reader{data: data}
+		return &TransferFeeConfig{
+			TransferFeeConfigAuthority: r.optionalNonZeroPubkey(),
+			WithdrawWithheldAuthority:  r.optionalNonZeroPubkey(),
+			WithheldAmount:             r.uint64(),
+			OlderTransferFee:           TransferFee{Epoch: r.uint64(), MaximumFee: r.uint64(), TransferFeeBasisPoints: r.uint16()},
+			NewerTransferFee:           TransferFee{Epoch: r.uint64(), MaximumFee: r.uint64(), TransferFeeBasisPoints: r.uint16()},
+		}, nil
+
+	case ExtensionTransferFeeAmount:
+		if len(data) != transferFeeAmountSize {
+			return nil, fmt.Errorf("expected %d bytes, got %d", transferFeeAmountSize, len(data))
+		}
+		return &TransferFeeAmount{WithheldAmount: binary.LittleEndian.Uint64(data)}, nil
+
+	case ExtensionMetadataPointer:
+		if len(data) != metadataPointerSize {
+			return nil, fmt.Errorf("expected %d bytes, got %d", metadataPointerSize, len(data))
+		}
+		r := &reader{data: data}
+		return &MetadataPointer{
+			Authority:       r.optionalNonZeroPubkey(),
+			MetadataAddress: r.optionalNonZeroPubkey(),
+		}, nil
+
+	case ExtensionTokenMetadata:
+		return decodeTokenMetadata(data)
+	}
+	return nil, nil
+}
+
+func decodeTokenMetadata(data []byte) (*TokenMetadata, error) {
+	if len(data) < 2*solana.PublicKeyLength {
+		return nil, fmt.Errorf("token metadata too short: %d bytes", len(data))
+	}
+
+	r := &reader{data: data}
+	metadata := &TokenMetadata{
+		UpdateAuthority: r.optionalNonZeroPubkey(),
+		Mint:            r.pubkey(),
+	}
+
+	b := &borshReader{data: data, pos: r.pos}
+	metadata.Name = b.string()
+	metadata.Symbol = b.string()
+	metadata.URI = b.string()
+	count := b.uint32()
+	for i := uint32(0); i < count && b.err == nil; i++ {
+		metadata.AdditionalMetadata = append(metadata.AdditionalMetadata, [2]string{b.string(), b.string()})
+	}
+	if b.err != nil {
+		return nil, b.err
+	}
+	return metadata, nil
+}
+
+// borshReader reads variable length borsh data, recording the first
+// out-of-bounds read.
+type borshReader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (b *borshReader) next(n int) []byte {
+	if b.err != nil {
+		return nil
+	}
+	if n < 0 || b.pos+n > len(b.data) {
+		b.err = fmt.Errorf("token metadata truncated at byte %d", b.pos)
+		return nil
+	}
+	v := b.data[b.pos : b.pos+n]
+	b.pos += n
+	return v
+}
+
+func (b *borshReader) uint32() uint32 {
+	v := b.next(4)
+	if v == nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint32(v)
+}
+
+func (b *borshReader) string() string {
+	return string(b.next(int(b.uint32())))
+}
+
+func (m *Mint) extension(typ ExtensionType) any {
+	return findExtension(m.Extensions, typ)
+}
+
+func (a *Account) extension(typ ExtensionType) any {
+	return findExtension(a.Extensions, typ)
+}
+
+func findExtension(extensions []Extension, typ ExtensionType) any {
+	for _, ext := range extensions {
+		if ext.Type == typ {
+			return ext.Value
+		}
+	}
+	return nil
+}
+
+func (m *Mint) TransferFeeConfig() *TransferFeeConfig {
+	v, _ := m.extension(ExtensionTransferFeeConfig).(*TransferFeeConfig)
+	return v
+}
+
+func (m *Mint) MetadataPointer() *MetadataPointer {
+	v, _ := m.extension(ExtensionMetadataPointer).(*MetadataPointer)
+	return v
+}
+
+func (m *Mint) TokenMetadata() *TokenMetadata {
+	v, _ := m.extension(ExtensionTokenMetadata).(*TokenMetadata)
+	return v
+}
+
+func (a *Account) TransferFeeAmount() *TransferFeeAmount {
+	v, _ := a.extension(ExtensionTransferFeeAmount).(*TransferFeeAmount)
+	return v
+}
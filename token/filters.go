@@ -0,0 +1,51 @@
+package token
+
+import (
+	pb "github.com/andrew-solarstorm/yellowstone-grpc-client-go/proto"
+	"github.com/gagliardetto/solana-go"
+)
+
+const (
+	mintOffset  = 0
+	ownerOffset = 32
+)
+
+// AccountsByOwner returns a filter matching every token account of owner
+// under program. SPL Token accounts are matched by size; Token-2022 accounts
+// vary in size with their extensions, so the server's token account check is
+// used instead.
+func AccountsByOwner(program, owner solana.PublicKey) *pb.SubscribeRequestFilterAccounts {
+	return accountsFilter(program, ownerOffset, owner)
+}
+
+// AccountsByMint returns a filter matching every token account of mint under
+// program.
+func AccountsByMint(program, mint solana.PublicKey) *pb.SubscribeRequestFilterAccounts {
+	return accountsFilter(program, mintOffset, mint)
+}
+
+func accountsFilter(program solana.PublicKey, offset uint64, key solana.PublicKey) *pb.SubscribeRequestFilterAccounts {
+	filters := []*pb.SubscribeRequestFilterAccountsFilter{{
+		Filter: &pb.SubscribeRequestFilterAccountsFilter_Memcmp{
+			Memcmp: &pb.SubscribeRequestFilterAccountsFilterMemcmp{
+				Offset: offset,
+				Data:   &pb.SubscribeRequestFilterAccountsFilterMemcmp_Bytes{Bytes: key.Bytes()},
+			},
+		},
+	}}
+
+	if program.Equals(solana.TokenProgramID) {
+		filters = append(filters, &pb.SubscribeRequestFilterAccountsFilter{
+			Filter: &pb.SubscribeRequestFilterAccountsFilter_Datasize{Datasize: AccountSize},
+		})
+	} else {
+		filters = append(filters, &pb.SubscribeRequestFilterAccountsFilter{
+			Filter: &pb.SubscribeRequestFilterAccountsFilter_TokenAccountState{TokenAccountState: true},
+		})
+	}
+
+	return &pb.SubscribeRequestFilterAccounts{
+		Owner:   []string{program.String()},
+		Filters: filters,
+	}
+}
@@ -0,0 +1,36 @@
+package token_test
+
+import (
+	"testing"
+
+	yellowstone "github.com/andrew-solarstorm/yellowstone-grpc-client-go"
+	pb "github.com/andrew-solarstorm/yellowstone-grpc-client-go/proto"
+	"github.com/andrew-solarstorm/yellowstone-grpc-client-go/token"
+	"github.com/gagliardetto/solana-go"
+)
+
+func TestFilterPresets(t *testing.T) {
+	owner := solana.NewWallet().PublicKey()
+	mint := solana.NewWallet().PublicKey()
+
+	request := &pb.SubscribeRequest{
+		Accounts: map[string]*pb.SubscribeRequestFilterAccounts{
+			"spl":  token.AccountsByOwner(solana.TokenProgramID, owner),
+			"2022": token.AccountsByMint(solana.Token2022ProgramID, mint),
+		},
+	}
+	if err := yellowstone.ValidateSubscribeRequest(request); err != nil {
+		t.Fatalf("Presets failed validation: %v", err)
+	}
+
+	spl := request.Accounts["spl"]
+	memcmp := spl.Filters[0].GetMemcmp()
+	if spl.Owner[0] != solana.TokenProgramID.String() || memcmp.Offset != 32 || spl.Filters[1].GetDatasize() != token.AccountSize {
+		t.Errorf("Unexpected SPL preset: %v", spl)
+	}
+
+	t22 := request.Accounts["2022"]
+	if t22.Filters[0].GetMemcmp().Offset != 0 || !t22.Filters[1].GetTokenAccountState() {
+		t.Errorf("Unexpected Token-2022 preset: %v", t22)
+	}
+}
@@ -0,0 +1,273 @@
+// Package token decodes SPL Token and Token-2022 account data streamed in
+// account updates.
+package token
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	pb "github.com/andrew-solarstorm/yellowstone-grpc-client-go/proto"
+	"github.com/gagliardetto/solana-go"
+)
+
+const (
+	MintSize     = 82
+	AccountSize  = 165
+	MultisigSize = 355
+
+	// MaxSigners is the number of signer slots in a multisig account.
+	MaxSigners = 11
+)
+
+type Kind int
+
+const (
+	KindUnknown Kind = iota
+	KindMint
+	KindAccount
+	KindMultisig
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindMint:
+		return "mint"
+	case KindAccount:
+		return "account"
+	case KindMultisig:
+		return "multisig"
+	default:
+		return "unknown"
+	}
+}
+
+type AccountState uint8
+
+const (
+	AccountStateUninitialized AccountState = iota
+	AccountStateInitialized
+	AccountStateFrozen
+)
+
+type Mint struct {
+	MintAuthority   *solana.PublicKey
+	Supply          uint64
+	Decimals        uint8
+	IsInitialized   bool
+	FreezeAuthority *solana.PublicKey
+	// Extensions is only set for Token-2022 mints.
+	Extensions []Extension
+}
+
+type Account struct {
+	Mint            solana.PublicKey
+	Owner           solana.PublicKey
+	Amount          uint64
+	Delegate        *solana.PublicKey
+	State           AccountState
+	IsNative        *uint64
+	DelegatedAmount uint64
+	CloseAuthority  *solana.PublicKey
+	// Extensions is only set for Token-2022 accounts.
+	Extensions []Extension
+}
+
+type Multisig struct {
+	M             uint8
+	N             uint8
+	IsInitialized bool
+	Signers       []solana.PublicKey
+}
+
+func IsTokenProgram(owner solana.PublicKey) bool {
+	return owner.Equals(solana.TokenProgramID) || owner.Equals(solana.Token2022ProgramID)
+}
+
+// DetectKind classifies account data by its owner program and size. Token-2022
+// accounts larger than the base account layout carry their kind in the
+// account type byte that precedes the extensions.
+func DetectKind(owner solana.PublicKey, data []byte) Kind {
+	if !IsTokenProgram(owner) {
+		return KindUnknown
+	}
+
+	switch len(data) {
+	case MintSize:
+		return KindMint
+	case AccountSize:
+		return KindAccount
+	case MultisigSize:
+		return KindMultisig
+	}
+
+	if owner.Equals(solana.Token2022ProgramID) && len(data) > AccountSize {
+		switch accountType(data[AccountSize]) {
+		case accountTypeMint:
+			return KindMint
+		case accountTypeAccount:
+			return KindAccount
+		}
+	}
+	return KindUnknown
+}
+
+// Decode returns a *Mint, *Account or *Multisig depending on DetectKind.
+func Decode(owner solana.PublicKey, data []byte) (any, error) {
+	switch DetectKind(owner, data) {
+	case KindMint:
+		return DecodeMint(data)
+	case KindAccount:
+		return DecodeAccount(data)
+	case KindMultisig:
+		return DecodeMultisig(data)
+	default:
+		return nil, fmt.Errorf("not a token account: owner %s, %d bytes", owner, len(data))
+	}
+}
+
+func DecodeAccountInfo(info *pb.SubscribeUpdateAccountInfo) (any, error) {
+	if len(info.GetOwner()) != solana.PublicKeyLength {
+		return nil, fmt.Errorf("invalid owner length %d", len(info.GetOwner()))
+	}
+	return Decode(solana.PublicKeyFromBytes(info.GetOwner()), info.GetData())
+}
+
+func DecodeMint(data []byte) (*Mint, error) {
+	if len(data) < MintSize {
+		return nil, fmt.Errorf("mint data too short: %d bytes", len(data))
+	}
+
+	r := &reader{data: data}
+	mint := &Mint{
+		MintAuthority:   r.optionPubkey(),
+		Supply:          r.uint64(),
+		Decimals:        r.uint8(),
+		IsInitialized:   r.bool(),
+		FreezeAuthority: r.optionPubkey(),
+	}
+
+	if len(data) > MintSize {
+		extensions, err := decodeExtensions(data, accountTypeMint)
+		if err != nil {
+			return nil, err
+		}
+		mint.Extensions = extensions
+	}
+	return mint, nil
+}
+
+func DecodeAccount(data []byte) (*Account, error) {
+	if len(data) < AccountSize {
+		return nil, fmt.Errorf("token account data too short: %d bytes", len(data))
+	}
+
+	r := &reader{data: data}
+	account := &Account{
+		Mint:     r.pubkey(),
+		Owner:    r.pubkey(),
+		Amount:   r.uint64(),
+		Delegate: r.optionPubkey(),
+		State:    AccountState(r.uint8()),
+	}
+	if r.uint32() == 1 {
+		native := r.uint64()
+		account.IsNative = &native
+	} else {
+		r.skip(8)
+	}
+	account.DelegatedAmount = r.uint64()
+	account.CloseAuthority = r.optionPubkey()
+
+	if len(data) > AccountSize {
+		extensions, err := decodeExtensions(data, accountTypeAccount)
+		if err != nil {
+			return nil, err
+		}
+		account.Extensions = extensions
+	}
+	return account, nil
+}
+
+func DecodeMultisig(data []byte) (*Multisig, error) {
+	if len(data) != MultisigSize {
+		return nil, fmt.Errorf("multisig data must be %d bytes, got %d", MultisigSize, len(data))
+	}
+
+	r := &reader{data: data}
+	multisig := &Multisig{
+		M:             r.uint8(),
+		N:             r.uint8(),
+		IsInitialized: r.bool(),
+	}
+	if multisig.N > MaxSigners {
+		return nil, fmt.Errorf("multisig has %d signers, at most %d allowed", multisig.N, MaxSigners)
+	}
+	for i := 0; i < int(multisig.N); i++ {
+		multisig.Signers = append(multisig.Signers, r.pubkey())
+	}
+	return multisig, nil
+}
+
+// reader reads fixed layouts whose length was checked up front.
+type reader struct {
+	data []byte
+	pos  int
+}
+
+func (r *reader) skip(n int) {
+	r.pos += n
+}
+
+func (r *reader) uint8() uint8 {
+	v := r.data[r.pos]
+	r.pos++
+	return v
+}
+
+func (r *reader) bool() bool {
+	return r.uint8() != 0
+}
+
+func (r *reader) uint16() uint16 {
+	v := binary.LittleEndian.Uint16(r.data[r.pos:])
+	r.pos += 2
+	return v
+}
+
+func (r *reader) uint32() uint32 {
+	v := binary.LittleEndian.Uint32(r.data[r.pos:])
+	r.pos += 4
+	return v
+}
+
+func (r *reader) uint64() uint64 {
+	v := binary.LittleEndian.Uint64(r.data[r.pos:])
+	r.pos += 8
+	return v
+}
+
+func (r *reader) pubkey() solana.PublicKey {
+	key := solana.PublicKeyFromBytes(r.data[r.pos : r.pos+solana.PublicKeyLength])
+	r.pos += solana.PublicKeyLength
+	return key
+}
+
+// optionPubkey reads a COption<Pubkey>: a four byte tag followed by the key.
+func (r *reader) optionPubkey() *solana.PublicKey {
+	tag := r.uint32()
+	key := r.pubkey()
+	if tag == 0 {
+		return nil
+	}
+	return &key
+}
+
+// optionalNonZeroPubkey reads the Token-2022 encoding where an all-zero key
+// means none.
+func (r *reader) optionalNonZeroPubkey() *solana.PublicKey {
+	key := r.pubkey()
+	if key.IsZero() {
+		return nil
+	}
+	return &key
+}
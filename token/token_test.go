@@ -0,0 +1,171 @@
+package token
+
+import (
+	"encoding/binary"
+	"testing"
+
+	pb "github.com/andrew-solarstorm/yellowstone-grpc-client-go/proto"
+	"github.com/gagliardetto/solana-go"
+)
+
+type layout struct {
+	buf []byte
+}
+
+func (l *layout) u8(v uint8) *layout   { l.buf = append(l.buf, v); return l }
+func (l *layout) u16(v uint16) *layout { l.buf = binary.LittleEndian.AppendUint16(l.buf, v); return l }
+func (l *layout) u32(v uint32) *layout { l.buf = binary.LittleEndian.AppendUint32(l.buf, v); return l }
+func (l *layout) u64(v uint64) *layout { l.buf = binary.LittleEndian.AppendUint64(l.buf, v); return l }
+func (l *layout) key(k solana.PublicKey) *layout {
+	l.buf = append(l.buf, k.Bytes()...)
+	return l
+}
+func (l *layout) option(k *solana.PublicKey) *layout {
+	if k == nil {
+		return l.u32(0).key(solana.PublicKey{})
+	}
+	return l.u32(1).key(*k)
+}
+func (l *layout) str(s string) *layout {
+	l.u32(uint32(len(s)))
+	l.buf = append(l.buf, s...)
+	return l
+}
+func (l *layout) tlv(typ ExtensionType, value []byte) *layout {
+	l.u16(uint16(typ)).u16(uint16(len(value)))
+	l.buf = append(l.buf, value...)
+	return l
+}
+func (l *layout) pad(size int) *layout {
+	for len(l.buf) < size {
+		l.buf = append(l.buf, 0)
+	}
+	return l
+}
+
+func mintLayout(authority *solana.PublicKey) *layout {
+	return (&layout{}).option(authority).u64(1_000_000).u8(6).u8(1).option(nil)
+}
+
+func accountLayout(mint, owner solana.PublicKey) *layout {
+	native := uint64(2039280)
+	l := (&layout{}).key(mint).key(owner).u64(42).option(nil).u8(uint8(AccountStateFrozen))
+	l.u32(1).u64(native)
+	return l.u64(0).option(&owner)
+}
+
+func TestDecodeSPLToken(t *testing.T) {
+	authority := solana.NewWallet().PublicKey()
+	mint := solana.NewWallet().PublicKey()
+	owner := solana.NewWallet().PublicKey()
+
+	mintData := mintLayout(&authority).buf
+	if kind := DetectKind(solana.TokenProgramID, mintData); kind != KindMint {
+		t.Fatalf("Expected mint, got %s", kind)
+	}
+	decoded, err := Decode(solana.TokenProgramID, mintData)
+	if err != nil {
+		t.Fatalf("Decode mint failed: %v", err)
+	}
+	m := decoded.(*Mint)
+	if m.MintAuthority == nil || !m.MintAuthority.Equals(authority) || m.Supply != 1_000_000 || m.Decimals != 6 || !m.IsInitialized || m.FreezeAuthority != nil {
+		t.Errorf("Unexpected mint: %+v", m)
+	}
+
+	accountData := accountLayout(mint, owner).buf
+	info := &pb.SubscribeUpdateAccountInfo{Owner: solana.TokenProgramID.Bytes(), Data: accountData}
+	decoded, err = DecodeAccountInfo(info)
+	if err != nil {
+		t.Fatalf("Decode account failed: %v", err)
+	}
+	a := decoded.(*Account)
+	if !a.Mint.Equals(mint) || !a.Owner.Equals(owner) || a.Amount != 42 || a.State != AccountStateFrozen ||
+		a.IsNative == nil || *a.IsNative != 2039280 || a.Delegate != nil || a.CloseAuthority == nil {
+		t.Errorf("Unexpected account: %+v", a)
+	}
+
+	signers := []solana.PublicKey{solana.NewWallet().PublicKey(), solana.NewWallet().PublicKey()}
+	multisigData := (&layout{}).u8(1).u8(2).u8(1).key(signers[0]).key(signers[1]).pad(MultisigSize).buf
+	decoded, err = Decode(solana.TokenProgramID, multisigData)
+	if err != nil {
+		t.Fatalf("Decode multisig failed: %v", err)
+	}
+	ms := decoded.(*Multisig)
+	if ms.M != 1 || ms.N != 2 || len(ms.Signers) != 2 || !ms.Signers[1].Equals(signers[1]) {
+		t.Errorf("Unexpected multisig: %+v", ms)
+	}
+
+	if DetectKind(solana.SystemProgramID, mintData) != KindUnknown {
+		t.Error("Expected unknown kind for non-token owner")
+	}
+	if _, err := Decode(solana.TokenProgramID, make([]byte, 100)); err == nil {
+		t.Error("Expected error for unknown size")
+	}
+}
+
+func TestDecodeToken2022Extensions(t *testing.T) {
+	authority := solana.NewWallet().PublicKey()
+	mintKey := solana.NewWallet().PublicKey()
+
+	fee := (&layout{}).key(authority).key(solana.PublicKey{}).u64(7).
+		u64(100).u64(5000).u16(25).
+		u64(200).u64(9000).u16(50).buf
+	pointer := (&layout{}).key(authority).key(mintKey).buf
+	metadata := (&layout{}).key(authority).key(mintKey).str("Test").str("TST").str("https://example.com").
+		u32(1).str("k").str("v").buf
+
+	data := mintLayout(&authority).pad(AccountSize).u8(uint8(accountTypeMint)).
+		tlv(ExtensionTransferFeeConfig, fee).
+		tlv(ExtensionMetadataPointer, pointer).
+		tlv(ExtensionTokenMetadata, metadata).buf
+
+	if kind := DetectKind(solana.Token2022ProgramID, data); kind != KindMint {
+		t.Fatalf("Expected mint, got %s", kind)
+	}
+	decoded, err := Decode(solana.Token2022ProgramID, data)
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	m := decoded.(*Mint)
+	if len(m.Extensions) != 3 || m.Decimals != 6 {
+		t.Fatalf("Unexpected mint: %+v", m)
+	}
+
+	cfg := m.TransferFeeConfig()
+	if cfg == nil || cfg.WithdrawWithheldAuthority != nil || cfg.WithheldAmount != 7 {
+		t.Fatalf("Unexpected transfer fee config: %+v", cfg)
+	}
+	if f := cfg.Fee(150); f.TransferFeeBasisPoints != 25 {
+		t.Errorf("Expected older fee before epoch 200, got %+v", f)
+	}
+	if f := cfg.Fee(200); f.TransferFeeBasisPoints != 50 || f.MaximumFee != 9000 {
+		t.Errorf("Expected newer fee from epoch 200, got %+v", f)
+	}
+
+	if p := m.MetadataPointer(); p == nil || !p.MetadataAddress.Equals(mintKey) {
+		t.Errorf("Unexpected metadata pointer: %+v", p)
+	}
+	md := m.TokenMetadata()
+	if md == nil || md.Name != "Test" || md.Symbol != "TST" || md.URI != "https://example.com" ||
+		len(md.AdditionalMetadata) != 1 || md.AdditionalMetadata[0] != [2]string{"k", "v"} {
+		t.Errorf("Unexpected token metadata: %+v", md)
+	}
+
+	owner := solana.NewWallet().PublicKey()
+	accountData := accountLayout(mintKey, owner).u8(uint8(accountTypeAccount)).
+		tlv(ExtensionTransferFeeAmount, (&layout{}).u64(11).buf).
+		tlv(ExtensionImmutableOwner, nil).buf
+	decoded, err = Decode(solana.Token2022ProgramID, accountData)
+	if err != nil {
+		t.Fatalf("Decode account failed: %v", err)
+	}
+	a := decoded.(*Account)
+	if amount := a.TransferFeeAmount(); amount == nil || amount.WithheldAmount != 11 || len(a.Extensions) != 2 {
+		t.Errorf("Unexpected account extensions: %+v", a.Extensions)
+	}
+
+	truncated := append([]byte{}, data[:len(data)-5]...)
+	if _, err := Decode(solana.Token2022ProgramID, truncated); err == nil {
+		t.Error("Expected error for truncated extension")
+	}
+}
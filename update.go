@@ -0,0 +1,26 @@
+package yellowstone
+
+import (
+	pb "github.com/andrew-solarstorm/yellowstone-grpc-client-go/proto"
+)
+
+func UpdateSlot(update *pb.SubscribeUpdate) (uint64, bool) {
+	switch u := update.GetUpdateOneof().(type) {
+	case *pb.SubscribeUpdate_Account:
+		return u.Account.GetSlot(), true
+	case *pb.SubscribeUpdate_Slot:
+		return u.Slot.GetSlot(), true
+	case *pb.SubscribeUpdate_Transaction:
+		return u.Transaction.GetSlot(), true
+	case *pb.SubscribeUpdate_TransactionStatus:
+		return u.TransactionStatus.GetSlot(), true
+	case *pb.SubscribeUpdate_Block:
+		return u.Block.GetSlot(), true
+	case *pb.SubscribeUpdate_BlockMeta:
+		return u.BlockMeta.GetSlot(), true
+	case *pb.SubscribeUpdate_Entry:
+		return u.Entry.GetSlot(), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,115 @@
+package yellowstone
+
+import (
+	"context"
+	"errors"
+	"io"
+	"iter"
+
+	pb "github.com/andrew-solarstorm/yellowstone-grpc-client-go/proto"
+)
+
+// Updates opens a stream for request and delivers its updates on a channel
+// with the given buffer. The error channel yields at most one error and both
+// channels are closed when the stream ends. Unlike Start, the client stays
+// usable afterwards.
+func (c *GeyserGrpcClient) Updates(
+	ctx context.Context,
+	request *pb.SubscribeRequest,
+	buffer int,
+) (<-chan *pb.SubscribeUpdate, <-chan error) {
+	return pumpUpdates(ctx, buffer, func(ctx context.Context, fn func(*pb.SubscribeUpdate) error) error {
+		return c.runStream(ctx, request, fn)
+	})
+}
+
+func (c *GeyserGrpcClient) UpdatesSeq(
+	ctx context.Context,
+	request *pb.SubscribeRequest,
+	buffer int,
+) iter.Seq2[*pb.SubscribeUpdate, error] {
+	return seqUpdates(ctx, func(ctx context.Context) (<-chan *pb.SubscribeUpdate, <-chan error) {
+		return c.Updates(ctx, request, buffer)
+	})
+}
+
+func (s *Subscription) Updates(ctx context.Context, buffer int) (<-chan *pb.SubscribeUpdate, <-chan error) {
+	return pumpUpdates(ctx, buffer, s.Run)
+}
+
+func (s *Subscription) UpdatesSeq(ctx context.Context, buffer int) iter.Seq2[*pb.SubscribeUpdate, error] {
+	return seqUpdates(ctx, func(ctx context.Context) (<-chan *pb.SubscribeUpdate, <-chan error) {
+		return s.Updates(ctx, buffer)
+	})
+}
+
+func (c *GeyserGrpcClient) runStream(
+	ctx context.Context,
+	request *pb.SubscribeRequest,
+	fn func(*pb.SubscribeUpdate) error,
+) error {
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := c.SubscribeWithRequest(streamCtx, request)
+	if err != nil {
+		return err
+	}
+
+	_, streamErr, fnErr := c.receive(stream, fn)
+	if fnErr != nil {
+		return fnErr
+	}
+	if errors.Is(streamErr, io.EOF) || ctx.Err() != nil || c.ctx.Err() != nil {
+		return nil
+	}
+	return wrapStreamError(streamErr)
+}
+
+func pumpUpdates(
+	ctx context.Context,
+	buffer int,
+	run func(context.Context, func(*pb.SubscribeUpdate) error) error,
+) (<-chan *pb.SubscribeUpdate, <-chan error) {
+	updates := make(chan *pb.SubscribeUpdate, buffer)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+		defer close(updates)
+
+		err := run(ctx, func(update *pb.SubscribeUpdate) error {
+			select {
+			case updates <- update:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+		if err != nil && ctx.Err() == nil {
+			errs <- err
+		}
+	}()
+
+	return updates, errs
+}
+
+func seqUpdates(
+	ctx context.Context,
+	open func(context.Context) (<-chan *pb.SubscribeUpdate, <-chan error),
+) iter.Seq2[*pb.SubscribeUpdate, error] {
+	return func(yield func(*pb.SubscribeUpdate, error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		updates, errs := open(ctx)
+		for update := range updates {
+			if !yield(update, nil) {
+				return
+			}
+		}
+		if err := <-errs; err != nil {
+			yield(nil, err)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package yellowstone
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func sendSlotsServer(count int, err error, wait bool) *testGeyserServer {
+	return &testGeyserServer{
+		subscribe: func(stream pbSubscribeServer) error {
+			if _, err := stream.Recv(); err != nil {
+				return err
+			}
+			for slot := 1; slot <= count; slot++ {
+				if err := stream.Send(slotUpdate(uint64(slot))); err != nil {
+					return err
+				}
+			}
+			if err != nil {
+				return err
+			}
+			if wait {
+				<-stream.Context().Done()
+			}
+			return nil
+		},
+	}
+}
+
+func TestUpdatesChannel(t *testing.T) {
+	client := connectTestClient(t, startTestServer(t, sendSlotsServer(3, nil, false)))
+
+	for run := 0; run < 2; run++ {
+		updates, errs := client.Updates(context.Background(), slotsRequest(), 4)
+
+		var slots []uint64
+		for update := range updates {
+			slots = append(slots, update.GetSlot().GetSlot())
+		}
+		if err := <-errs; err != nil {
+			t.Fatalf("Run %d: expected clean end of stream, got %v", run, err)
+		}
+		if len(slots) != 3 || slots[2] != 3 {
+			t.Fatalf("Run %d: unexpected slots %v", run, slots)
+		}
+	}
+}
+
+func TestUpdatesChannelError(t *testing.T) {
+	client := connectTestClient(t, startTestServer(t, sendSlotsServer(1, status.Error(codes.InvalidArgument, "bad filter"), false)))
+
+	updates, errs := client.Updates(context.Background(), slotsRequest(), 0)
+	count := 0
+	for range updates {
+		count++
+	}
+	err := <-errs
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("Expected InvalidArgument, got %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Expected 1 update before error, got %d", count)
+	}
+}
+
+func TestUpdatesContextCancel(t *testing.T) {
+	client := connectTestClient(t, startTestServer(t, sendSlotsServer(0, nil, true)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	updates, errs := client.Updates(ctx, slotsRequest(), 0)
+	cancel()
+
+	select {
+	case <-errs:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Updates did not stop after context cancellation")
+	}
+	for range updates {
+	}
+
+	if _, err := client.GetVersion(context.Background()); err != nil {
+		t.Errorf("Expected client to remain usable, got %v", err)
+	}
+}
+
+func TestUpdatesSeq(t *testing.T) {
+	client := connectTestClient(t, startTestServer(t, sendSlotsServer(5, nil, false)))
+
+	var slots []uint64
+	for update, err := range client.UpdatesSeq(context.Background(), slotsRequest(), 1) {
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		slots = append(slots, update.GetSlot().GetSlot())
+		if len(slots) == 2 {
+			break
+		}
+	}
+	if len(slots) != 2 {
+		t.Fatalf("Expected to stop after 2 updates, got %v", slots)
+	}
+
+	var gotErr error
+	client = connectTestClient(t, startTestServer(t, sendSlotsServer(0, status.Error(codes.PermissionDenied, "no"), false)))
+	for _, err := range client.UpdatesSeq(context.Background(), slotsRequest(), 0) {
+		gotErr = err
+	}
+	if status.Code(gotErr) != codes.PermissionDenied {
+		t.Fatalf("Expected PermissionDenied from iterator, got %v", gotErr)
+	}
+}
+
+func TestSubscriptionUpdatesSeq(t *testing.T) {
+	client := connectTestClient(t, startTestServer(t, sendSlotsServer(1, nil, true)))
+	sub := client.SubscribeWithReconnect(slotsRequest(), DefaultReconnectPolicy())
+
+	for update, err := range sub.UpdatesSeq(context.Background(), 0) {
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if update.GetSlot() == nil {
+			t.Fatalf("Unexpected update %v", update)
+		}
+		break
+	}
+}
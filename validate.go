@@ -0,0 +1,229 @@
+package yellowstone
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+
+	pb "github.com/andrew-solarstorm/yellowstone-grpc-client-go/proto"
+	"github.com/gagliardetto/solana-go"
+	"github.com/mr-tron/base58"
+)
+
+const MaxMemcmpDataSize = 128
+
+// ValidateSubscribeRequest checks a request for mistakes the server would
+// otherwise reject after the stream opens. All problems are returned joined,
+// each as a *FilterError naming the offending filter.
+func ValidateSubscribeRequest(request *pb.SubscribeRequest) error {
+	v := &requestValidator{}
+
+	if request == nil {
+		return nil
+	}
+
+	if request.Ping == nil && isEmptyRequest(request) {
+		v.fail("request", "", "", "no filters set")
+	}
+	v.filters(request)
+
+	return errors.Join(v.errs...)
+}
+
+// validateSubscribeFilters applies every check but the one for a request
+// without filters, which a Subscription reaches by dropping its last filter.
+func validateSubscribeFilters(request *pb.SubscribeRequest) error {
+	v := &requestValidator{}
+
+	if request == nil {
+		return nil
+	}
+
+	v.filters(request)
+
+	return errors.Join(v.errs...)
+}
+
+func (v *requestValidator) filters(request *pb.SubscribeRequest) {
+	for _, name := range sortedKeys(request.Accounts) {
+		v.accounts(name, request.Accounts[name])
+	}
+	for _, name := range sortedKeys(request.Transactions) {
+		v.transactions("transactions", name, request.Transactions[name])
+	}
+	for _, name := range sortedKeys(request.TransactionsStatus) {
+		v.transactions("transactions_status", name, request.TransactionsStatus[name])
+	}
+	for _, name := range sortedKeys(request.Blocks) {
+		filter := request.Blocks[name]
+		if filter == nil {
+			v.fail("blocks", name, "", "filter is nil")
+			continue
+		}
+		v.pubkeys("blocks", name, "account_include", filter.AccountInclude)
+	}
+
+	v.dataSlices(request.AccountsDataSlice)
+
+	if request.FromSlot != nil {
+		if len(request.Blocks) > 0 {
+			v.fail("blocks", "", "", "from_slot replay is not supported for block filters")
+		}
+		if len(request.Entry) > 0 {
+			v.fail("entry", "", "", "from_slot replay is not supported for entry filters")
+		}
+	}
+}
+
+// ValidateSubscribeDeshredRequest applies the same checks to the deshred
+// transaction filters of a SubscribeDeshred request.
+func ValidateSubscribeDeshredRequest(request *pb.SubscribeDeshredRequest) error {
+	v := &requestValidator{}
+
+	if request == nil {
+		return nil
+	}
+
+	if request.Ping == nil && len(request.DeshredTransactions) == 0 {
+		v.fail("request", "", "", "no filters set")
+	}
+
+	for _, name := range sortedKeys(request.DeshredTransactions) {
+		filter := request.DeshredTransactions[name]
+		if filter == nil {
+			v.fail("deshred_transactions", name, "", "filter is nil")
+			continue
+		}
+		v.pubkeys("deshred_transactions", name, "account_include", filter.AccountInclude)
+		v.pubkeys("deshred_transactions", name, "account_exclude", filter.AccountExclude)
+		v.pubkeys("deshred_transactions", name, "account_required", filter.AccountRequired)
+		v.conflicts("deshred_transactions", name, filter.AccountInclude, filter.AccountExclude)
+	}
+
+	return errors.Join(v.errs...)
+}
+
+func isEmptyRequest(request *pb.SubscribeRequest) bool {
+	return len(request.Accounts) == 0 &&
+		len(request.Slots) == 0 &&
+		len(request.Transactions) == 0 &&
+		len(request.TransactionsStatus) == 0 &&
+		len(request.Blocks) == 0 &&
+		len(request.BlocksMeta) == 0 &&
+		len(request.Entry) == 0
+}
+
+type requestValidator struct {
+	errs []error
+}
+
+func (v *requestValidator) fail(kind, name, field, format string, args ...any) {
+	v.errs = append(v.errs, &FilterError{
+		Kind:    kind,
+		Name:    name,
+		Field:   field,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *requestValidator) pubkeys(kind, name, field string, keys []string) {
+	for _, key := range keys {
+		if _, err := solana.PublicKeyFromBase58(key); err != nil {
+			v.fail(kind, name, field, "invalid pubkey %q", key)
+		}
+	}
+}
+
+func (v *requestValidator) accounts(name string, filter *pb.SubscribeRequestFilterAccounts) {
+	if filter == nil {
+		v.fail("accounts", name, "", "filter is nil")
+		return
+	}
+
+	v.pubkeys("accounts", name, "account", filter.Account)
+	v.pubkeys("accounts", name, "owner", filter.Owner)
+
+	for _, f := range filter.Filters {
+		memcmp := f.GetMemcmp()
+		if memcmp == nil {
+			continue
+		}
+
+		var (
+			data []byte
+			err  error
+		)
+		switch d := memcmp.GetData().(type) {
+		case *pb.SubscribeRequestFilterAccountsFilterMemcmp_Bytes:
+			data = d.Bytes
+		case *pb.SubscribeRequestFilterAccountsFilterMemcmp_Base58:
+			data, err = base58.Decode(d.Base58)
+		case *pb.SubscribeRequestFilterAccountsFilterMemcmp_Base64:
+			data, err = base64.StdEncoding.DecodeString(d.Base64)
+		default:
+			v.fail("accounts", name, "memcmp", "missing data at offset %d", memcmp.GetOffset())
+			continue
+		}
+
+		if err != nil {
+			v.fail("accounts", name, "memcmp", "invalid data encoding: %v", err)
+			continue
+		}
+		if len(data) > MaxMemcmpDataSize {
+			v.fail("accounts", name, "memcmp", "data length %d exceeds %d bytes", len(data), MaxMemcmpDataSize)
+		}
+	}
+}
+
+func (v *requestValidator) transactions(kind, name string, filter *pb.SubscribeRequestFilterTransactions) {
+	if filter == nil {
+		v.fail(kind, name, "", "filter is nil")
+		return
+	}
+
+	v.pubkeys(kind, name, "account_include", filter.AccountInclude)
+	v.pubkeys(kind, name, "account_exclude", filter.AccountExclude)
+	v.pubkeys(kind, name, "account_required", filter.AccountRequired)
+
+	if filter.Signature != nil {
+		if _, err := solana.SignatureFromBase58(*filter.Signature); err != nil {
+			v.fail(kind, name, "signature", "invalid signature %q", *filter.Signature)
+		}
+	}
+
+	v.conflicts(kind, name, filter.AccountInclude, filter.AccountExclude)
+}
+
+func (v *requestValidator) conflicts(kind, name string, include, exclude []string) {
+	excluded := make(map[string]struct{}, len(exclude))
+	for _, key := range exclude {
+		excluded[key] = struct{}{}
+	}
+	for _, key := range include {
+		if _, ok := excluded[key]; ok {
+			v.fail(kind, name, "account_exclude", "pubkey %s is both included and excluded", key)
+		}
+	}
+}
+
+func (v *requestValidator) dataSlices(slices []*pb.SubscribeRequestAccountsDataSlice) {
+	for i := 1; i < len(slices); i++ {
+		prev, cur := slices[i-1], slices[i]
+		if cur.GetOffset() < prev.GetOffset() {
+			v.fail("accounts_data_slice", "", fmt.Sprint(i), "offset %d is before previous offset %d", cur.GetOffset(), prev.GetOffset())
+		} else if cur.GetOffset() < prev.GetOffset()+prev.GetLength() {
+			v.fail("accounts_data_slice", "", fmt.Sprint(i), "range [%d, %d) overlaps previous range [%d, %d)",
+				cur.GetOffset(), cur.GetOffset()+cur.GetLength(), prev.GetOffset(), prev.GetOffset()+prev.GetLength())
+		}
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
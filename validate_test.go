@@ -0,0 +1,135 @@
+package yellowstone
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	pb "github.com/andrew-solarstorm/yellowstone-grpc-client-go/proto"
+	"github.com/gagliardetto/solana-go"
+)
+
+func filterErrors(err error) []*FilterError {
+	var out []*FilterError
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, e := range joined.Unwrap() {
+			var filterErr *FilterError
+			if errors.As(e, &filterErr) {
+				out = append(out, filterErr)
+			}
+		}
+	}
+	return out
+}
+
+func TestValidateSubscribeRequestValid(t *testing.T) {
+	fromSlot := uint64(100)
+	req := &pb.SubscribeRequest{
+		Accounts: map[string]*pb.SubscribeRequestFilterAccounts{
+			"tokens": {Owner: []string{solana.TokenProgramID.String()}},
+		},
+		Transactions: map[string]*pb.SubscribeRequestFilterTransactions{
+			"txs": {AccountInclude: []string{solana.SystemProgramID.String()}},
+		},
+		AccountsDataSlice: []*pb.SubscribeRequestAccountsDataSlice{
+			{Offset: 0, Length: 32},
+			{Offset: 32, Length: 8},
+		},
+		FromSlot: &fromSlot,
+	}
+
+	if err := ValidateSubscribeRequest(req); err != nil {
+		t.Fatalf("Expected valid request, got %v", err)
+	}
+
+	ping := &pb.SubscribeRequest{Ping: &pb.SubscribeRequestPing{Id: 1}}
+	if err := ValidateSubscribeRequest(ping); err != nil {
+		t.Fatalf("Expected ping request to be valid, got %v", err)
+	}
+}
+
+func TestValidateSubscribeRequestErrors(t *testing.T) {
+	fromSlot := uint64(100)
+	system := solana.SystemProgramID.String()
+	req := &pb.SubscribeRequest{
+		Accounts: map[string]*pb.SubscribeRequestFilterAccounts{
+			"bad_owner": {Owner: []string{"invalid"}},
+			"big_memcmp": {Filters: []*pb.SubscribeRequestFilterAccountsFilter{{
+				Filter: &pb.SubscribeRequestFilterAccountsFilter_Memcmp{
+					Memcmp: &pb.SubscribeRequestFilterAccountsFilterMemcmp{
+						Data: &pb.SubscribeRequestFilterAccountsFilterMemcmp_Bytes{Bytes: make([]byte, 200)},
+					},
+				},
+			}}},
+		},
+		Transactions: map[string]*pb.SubscribeRequestFilterTransactions{
+			"conflict": {AccountInclude: []string{system}, AccountExclude: []string{system}},
+		},
+		Blocks: map[string]*pb.SubscribeRequestFilterBlocks{"blocks": {}},
+		AccountsDataSlice: []*pb.SubscribeRequestAccountsDataSlice{
+			{Offset: 10, Length: 10},
+			{Offset: 15, Length: 5},
+			{Offset: 0, Length: 5},
+		},
+		FromSlot: &fromSlot,
+	}
+
+	errs := filterErrors(ValidateSubscribeRequest(req))
+
+	expected := []string{
+		"accounts[bad_owner].owner",
+		"accounts[big_memcmp].memcmp",
+		"transactions[conflict].account_exclude",
+		"accounts_data_slice.1: range",
+		"accounts_data_slice.2: offset",
+		"blocks: from_slot",
+	}
+	for _, want := range expected {
+		found := false
+		for _, err := range errs {
+			if strings.HasPrefix(err.Error(), want) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("Expected error starting with %q in %v", want, errs)
+		}
+	}
+	if len(errs) != len(expected) {
+		t.Errorf("Expected %d errors, got %d: %v", len(expected), len(errs), errs)
+	}
+}
+
+func TestValidateSubscribeRequestEmpty(t *testing.T) {
+	errs := filterErrors(ValidateSubscribeRequest(&pb.SubscribeRequest{}))
+	if len(errs) != 1 || errs[0].Kind != "request" {
+		t.Fatalf("Expected empty request error, got %v", errs)
+	}
+}
+
+func TestSubscribeWithRequestValidatesBeforeSend(t *testing.T) {
+	opened := false
+	srv := &testGeyserServer{}
+	srv.subscribe = func(stream pbSubscribeServer) error {
+		opened = true
+		return nil
+	}
+	client := connectTestClient(t, startTestServer(t, srv))
+
+	_, err := client.SubscribeWithRequest(context.Background(), &pb.SubscribeRequest{
+		Accounts: map[string]*pb.SubscribeRequestFilterAccounts{"bad": {Account: []string{"xyz"}}},
+	})
+
+	var clientErr *GeyserGrpcClientError
+	if !errors.As(err, &clientErr) || clientErr.Type != "InvalidSubscribeRequest" {
+		t.Fatalf("Expected InvalidSubscribeRequest error, got %v", err)
+	}
+	var filterErr *FilterError
+	if !errors.As(err, &filterErr) || filterErr.Name != "bad" {
+		t.Fatalf("Expected FilterError for filter 'bad', got %v", err)
+	}
+	if opened {
+		t.Error("Expected no stream to be opened for invalid request")
+	}
+}